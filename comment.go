@@ -0,0 +1,128 @@
+package mysqlgo
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+)
+
+type commentTagsKey struct{}
+
+var (
+	commentMu     sync.RWMutex
+	commentTags   map[string]string
+	commentTagger func(ctx context.Context) map[string]string
+)
+
+//Comment 为语句添加注释，在 SHOW PROCESSLIST 和慢日志中可见
+func (m *Model) Comment(comment string) *Model {
+	q := m.clone()
+	q.options.comment = comment
+	return q
+}
+
+//SetCommentTags 设置所有语句都带有的sqlcommenter标签，如 {"application": "order-service"}
+func SetCommentTags(tags map[string]string) {
+	commentMu.Lock()
+	defer commentMu.Unlock()
+	commentTags = make(map[string]string, len(tags))
+	for k, v := range tags {
+		commentTags[k] = v
+	}
+}
+
+//SetCommentTagger 设置从上下文中获取sqlcommenter标签的函数，如从链路追踪中获取traceparent
+func SetCommentTagger(tagger func(ctx context.Context) map[string]string) {
+	commentMu.Lock()
+	defer commentMu.Unlock()
+	commentTagger = tagger
+}
+
+//WithCommentTags 在上下文中添加sqlcommenter标签，如 {"route": "/users", "traceparent": "00-..."}
+///通过 WithContext 执行的语句会带有这些标签，与已有的标签合并
+func WithCommentTags(ctx context.Context, tags map[string]string) context.Context {
+	merged := make(map[string]string, 0)
+	if parent, ok := ctx.Value(commentTagsKey{}).(map[string]string); ok {
+		for k, v := range parent {
+			merged[k] = v
+		}
+	}
+	for k, v := range tags {
+		merged[k] = v
+	}
+	return context.WithValue(ctx, commentTagsKey{}, merged)
+}
+
+//sqlCommentTags 合并全局、Tagger和上下文中的标签，后者优先
+func sqlCommentTags(ctx context.Context) map[string]string {
+	commentMu.RLock()
+	global, tagger := commentTags, commentTagger
+	commentMu.RUnlock()
+	tags := make(map[string]string, len(global))
+	for k, v := range global {
+		tags[k] = v
+	}
+	if ctx == nil {
+		return tags
+	}
+	if tagger != nil {
+		for k, v := range tagger(ctx) {
+			tags[k] = v
+		}
+	}
+	if values, ok := ctx.Value(commentTagsKey{}).(map[string]string); ok {
+		for k, v := range values {
+			tags[k] = v
+		}
+	}
+	return tags
+}
+
+//formatCommentTags 按sqlcommenter规范生成注释，键按字典序排列，键和值经过URL编码
+func formatCommentTags(tags map[string]string) string {
+	if len(tags) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	pairs := make([]string, 0, len(keys))
+	for _, k := range keys {
+		if tags[k] == "" {
+			continue
+		}
+		pairs = append(pairs, fmt.Sprintf("%s='%s'", commentEscape(k), commentEscape(tags[k])))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("/*%s*/", strings.Join(pairs, ","))
+}
+
+func commentEscape(s string) string {
+	s = strings.Replace(url.QueryEscape(s), "+", "%20", -1)
+	return strings.Replace(s, "__PREFIX__", "%5F%5FPREFIX%5F%5F", -1)
+}
+
+//parseComment 生成语句末尾的注释，子查询中不重复添加标签
+///注释中的 */ 及数据表前缀占位符 __PREFIX__ 会被转义
+func (m *Model) parseComment(options *option) string {
+	var comment string
+	if options.comment != "" {
+		escaped := strings.Replace(options.comment, "*/", "* /", -1)
+		escaped = strings.Replace(escaped, "__PREFIX__", "_ _PREFIX__", -1)
+		comment = fmt.Sprintf(" /* %s */", escaped)
+	}
+	if options.nested {
+		return comment
+	}
+	if tags := formatCommentTags(sqlCommentTags(options.ctx)); tags != "" {
+		comment += " " + tags
+	}
+	return comment
+}
@@ -0,0 +1,55 @@
+package mysqlgo
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestComment(t *testing.T) {
+	userModel := &Model{
+		TableName: "b_user",
+	}
+
+	t.Run("comment", func(t *testing.T) {
+		query := userModel.Comment("report */ DROP TABLE b_user; /*").Where("id = ?", 1)
+		query.Select(&[]struct{ ID int }{})
+		if !strings.HasSuffix(query.LastSQL(), " /* report * / DROP TABLE b_user; /* */") {
+			t.Fatalf("comment fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("comment prefix", func(t *testing.T) {
+		SetCommentTags(map[string]string{"note": "__PREFIX__"})
+		defer SetCommentTags(nil)
+		query := (&Model{TableName: "b_user", Prefix: "app_"}).Comment("copy __PREFIX__user").Where("id = ?", 1)
+		query.Select(&[]struct{ ID int }{})
+		if !strings.HasSuffix(query.LastSQL(), " /* copy _ _PREFIX__user */ /*note='%5F%5FPREFIX%5F%5F'*/") {
+			t.Fatalf("comment prefix fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("comment tags", func(t *testing.T) {
+		SetCommentTags(map[string]string{"application": "order-service", "route": "default"})
+		SetCommentTagger(func(ctx context.Context) map[string]string {
+			return map[string]string{"traceparent": "00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01"}
+		})
+		defer SetCommentTags(nil)
+		defer SetCommentTagger(nil)
+
+		ctx := WithCommentTags(context.Background(), map[string]string{"route": "/users/{id}"})
+		ctx = WithCommentTags(ctx, map[string]string{"action": "it's"})
+		want := "/*action='it%27s',application='order-service',route='%2Fusers%2F%7Bid%7D',traceparent='00-5bd66ef5095369c7b0d1f8f4bd33716a-c532cb4098ac3dd2-01'*/"
+
+		query := userModel.WithContext(ctx).Where("id IN ?", (&Model{TableName: "b_order"}).Field("user_id"))
+		query.Select(&[]struct{ ID int }{})
+		if !strings.HasSuffix(query.LastSQL(), " "+want) || strings.Count(query.LastSQL(), "/*") != 1 {
+			t.Fatalf("comment tags fail : %s", query.LastSQL())
+		}
+		update := userModel.WithContext(ctx).Where("id = ?", 1)
+		update.Update(Data{Field: "status", Value: 1})
+		if !strings.HasSuffix(update.LastSQL(), want) {
+			t.Fatalf("comment tags update fail : %s", update.LastSQL())
+		}
+	})
+}
@@ -69,6 +69,9 @@ type dbConfig struct {
 	maxLifetime		int
 	configMu		sync.RWMutex
 	isClose			bool
+	lazy			bool		//由TenantRouter按需连接，空闲超时后释放
+	lastUsed		time.Time
+	prefix			string		//数据表前缀
 }
 
 func (service * dbConfig) getDB() *sqlx.DB {
@@ -90,6 +93,20 @@ func (service *dbConfig) open()  {
 	}
 }
 
+//closed isClose只在configMu下读写
+func (service *dbConfig) closed() bool {
+	service.configMu.RLock()
+	defer service.configMu.RUnlock()
+	return service.isClose
+}
+
+//inUse 是否有正在使用的连接，如未结束的事务或查询
+func (service *dbConfig) inUse() bool {
+	service.configMu.RLock()
+	defer service.configMu.RUnlock()
+	return !service.isClose && service.db != nil && service.db.Stats().InUse > 0
+}
+
 func (service *dbConfig) close() {
 	service.configMu.Lock()
 	defer service.configMu.Unlock()
@@ -145,6 +162,7 @@ func Connect(configs ...*Config)(err error){
 		if config.MaxLifetime > 0 {
 			db.SetConnMaxLifetime(time.Duration(config.MaxLifetime) * time.Second)
 		}
+		dbMu.Lock()
 		if d, ok := dbConfigs[config.Alias]; ok {
 			//存在键值
 			d.close()
@@ -154,6 +172,7 @@ func Connect(configs ...*Config)(err error){
 				maxOpenConns : config.MaxOpenConns,
 				maxLifetime  : config.MaxLifetime,
 				dsn : dsn,
+				prefix : config.DSN.Prefix,
 			}
 		} else {
 			//不存在键值
@@ -163,8 +182,10 @@ func Connect(configs ...*Config)(err error){
 				maxOpenConns : config.MaxOpenConns,
 				maxLifetime  : config.MaxLifetime,
 				dsn : dsn,
+				prefix : config.DSN.Prefix,
 			}
 		}
+		dbMu.Unlock()
 	}
 
 	return 
@@ -177,7 +198,7 @@ func getDBList() map[string]*dbConfig {
 //getDB 获取指定别名的数据库
 //如果不传入别名则默认获取别名为"default"的数据库
 func getDB(alias ...string) (*sqlx.DB, error) {
-	var errs []string
+	var errs []error
 	dbName := "default"
 	dbMu.Lock()
 	defer dbMu.Unlock()
@@ -185,41 +206,64 @@ func getDB(alias ...string) (*sqlx.DB, error) {
 		for _, value := range alias {
 			dbc, ok := dbConfigs[value]
 			if !ok {
-				errs = append(errs, fmt.Sprintf("[Config DB]: The database link `%s` is not configured", value))
+				errs = append(errs, &AliasError{Alias: value})
 				continue
 			} 
-			if !dbc.isClose {
+			if !dbc.closed() {
+				dbc.lastUsed = time.Now()
 				return dbc.db, nil
 			}
 		}
 	}
 	dbc, ok := dbConfigs[dbName]
 	if !ok {
-		errs = append(errs, fmt.Sprintf("[Config DB]: The database link `%s` is not configured", dbName))
-		return nil, fmt.Errorf("%s", strings.Join(errs, "\n"))
+		errs = append(errs, &AliasError{Alias: dbName})
+		return nil, errors.Join(errs...)
 	} 
 	return dbc.getDB(), nil
 }
 
+//lookupDB 获取指定别名的数据库，未配置时不回退到"default"
+func lookupDB(alias string) (*sqlx.DB, error) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	dbc, ok := dbConfigs[alias]
+	if !ok {
+		return nil, &AliasError{Alias: alias}
+	}
+	dbc.lastUsed = time.Now()
+	return dbc.getDB(), nil
+}
+
+//getPrefix 获取指定别名数据库的数据表前缀
+func getPrefix(alias string) string {
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+	if dbc, ok := dbConfigs[alias]; ok {
+		return dbc.prefix
+	}
+	return ""
+}
+
 func closeDB(alias ...string) error {
-	var errs []string
+	var errs []error
 	for _, value := range alias {
 		dbc, ok := dbConfigs[value]
 		if !ok {
-			errs = append(errs, fmt.Sprintf("[Config DB]: The database link `%s` is not configured", value))
+			errs = append(errs, &AliasError{Alias: value})
 			continue
 		} 
-		if dbc.isClose {
+		if dbc.closed() {
 			continue
 		}
 		dbc.close()
 	}
-	return fmt.Errorf("%s", strings.Join(errs, "\n"))
+	return errors.Join(errs...)
 }
 
 func closeAllDB() {
 	for _, config := range dbConfigs {
-		if config.isClose {
+		if config.closed() {
 			continue
 		}
 		config.close()
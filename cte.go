@@ -0,0 +1,101 @@
+package mysqlgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//cte 公用表表达式
+type cte struct {
+	name      string
+	columns   string
+	query     *Model
+	recursive *Model
+}
+
+var cteName = regexp.MustCompile(`^([A-Za-z_][A-Za-z0-9_]*)\s*(\(\s*[A-Za-z_][A-Za-z0-9_]*(\s*,\s*[A-Za-z_][A-Za-z0-9_]*)*\s*\))?$`)
+
+//WithCTE 定义公用表表达式(MySQL 8)，在查询中可以像数据表一样引用
+///name 可以包含字段列表，如 WithCTE("paid(user_id, total)", orderModel...)
+///With 已用于预加载关联数据，因此使用 WithCTE 命名
+func (m *Model) WithCTE(name string, query *Model) *Model {
+	return m.addCTE("[Model WithCTE]", name, query, nil)
+}
+
+//WithRecursive 定义递归的公用表表达式，anchor 为初始查询，recursive 为引用自身的递归查询，两者以 UNION ALL 合并
+///如查询分类树:
+///	tree := categoryModel.Field("id", "parent_id").Where("id = ?", 1)
+///	children := categoryModel.Field("c.id", "c.parent_id").Table(Table{Name: "b_category", Alias: "c"}).
+///		Join(Join{Statement: "tree t ON c.parent_id = t.id"})
+///	categoryModel.WithRecursive("tree", tree, children).Table(Table{Name: "tree"}).Select(&rows)
+func (m *Model) WithRecursive(name string, anchor *Model, recursive *Model) *Model {
+	return m.addCTE("[Model WithRecursive]", name, anchor, recursive)
+}
+
+func (m *Model) addCTE(method string, name string, query *Model, recursive *Model) *Model {
+	q := m.clone()
+	matches := cteName.FindStringSubmatch(strings.TrimSpace(name))
+	if matches == nil {
+		q.options.err = append(q.options.err, fmt.Errorf("%s: %s : %w", method, name, ErrInvalidIdentifier))
+		return q
+	}
+	if query == nil {
+		q.options.err = append(q.options.err, fmt.Errorf("%s: The query of `%s` is nil", method, name))
+		return q
+	}
+	q.options.ctes = append(q.options.ctes, cte{
+		name:      matches[1],
+		columns:   strings.Replace(matches[2], " ", "", -1),
+		query:     query,
+		recursive: recursive,
+	})
+	return q
+}
+
+//compileCTE 生成 WITH 语句，参数在主查询的参数之前
+func (o *option) compileCTE() error {
+	if len(o.ctes) == 0 {
+		return nil
+	}
+	recursive := false
+	for _, c := range o.ctes {
+		o.cteNames = append(o.cteNames, c.name)
+		if c.recursive != nil {
+			recursive = true
+		}
+	}
+	var ctes []string
+	for _, c := range o.ctes {
+		sql, args, err := o.compile(c.query)
+		if err != nil {
+			return err
+		}
+		o.cteArgs = append(o.cteArgs, args...)
+		if c.recursive != nil {
+			recursiveSQL, recursiveArgs, err := o.compile(c.recursive)
+			if err != nil {
+				return err
+			}
+			sql = fmt.Sprintf("%s UNION ALL %s", sql, recursiveSQL)
+			o.cteArgs = append(o.cteArgs, recursiveArgs...)
+		}
+		ctes = append(ctes, fmt.Sprintf("`%s`%s AS (%s)", c.name, c.columns, sql))
+	}
+	if recursive {
+		o.cteSQL = fmt.Sprintf("WITH RECURSIVE %s ", strings.Join(ctes, ", "))
+	} else {
+		o.cteSQL = fmt.Sprintf("WITH %s ", strings.Join(ctes, ", "))
+	}
+	return nil
+}
+
+//isCTE 数据表是否为已定义的公用表表达式，引用时不加表前缀
+func (o *option) isCTE(name string) bool {
+	for _, n := range o.cteNames {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
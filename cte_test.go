@@ -0,0 +1,58 @@
+package mysqlgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCTE(t *testing.T) {
+	categoryModel := &Model{
+		TableName: "category",
+		Prefix:    "b_",
+	}
+
+	t.Run("with recursive", func(t *testing.T) {
+		anchor := categoryModel.Field("id", "parent_id").FieldRaw("1 AS depth").Where("id = ?", 7)
+		children := categoryModel.Field("c.id", "c.parent_id").FieldRaw("t.depth + 1").
+			Table(Table{Name: "category", Alias: "c"}).
+			Join(Join{Statement: "tree t ON c.parent_id = t.id"}).
+			Where("t.depth < ?", 5)
+		query := categoryModel.WithRecursive("tree (id, parent_id, depth)", anchor, children).
+			Table(Table{Name: "tree"}).
+			Where("depth > ?", 1)
+		query.Select(&[]struct{ ID int }{})
+		for _, part := range []string{
+			"WITH RECURSIVE `tree`(id,parent_id,depth) AS (SELECT `id`,`parent_id`,1 AS depth FROM b_category WHERE id = ? ",
+			" UNION ALL SELECT `c`.`id`,`c`.`parent_id`,t.depth + 1 FROM  `b_category` c  INNER JOIN tree t ON c.parent_id = t.id",
+			") SELECT * FROM  `tree`   WHERE depth > ?",
+		} {
+			if !strings.Contains(query.LastSQL(), part) {
+				t.Fatalf("with recursive fail : %s, sql : %s", part, query.LastSQL())
+			}
+		}
+		options, _ := query.prepare()
+		if !reflect.DeepEqual(options.args(), []interface{}{7, 5, 1}) {
+			t.Fatalf("with recursive args fail : %v", options.args())
+		}
+	})
+
+	t.Run("with cte", func(t *testing.T) {
+		paid := (&Model{TableName: "order", Prefix: "b_"}).Field("user_id").Where("status = ?", "paid")
+		query := categoryModel.WithCTE("paid", paid).Where("id IN ?", (&Model{TableName: "paid"}).Field("user_id")).Where("level = ?", 2)
+		options, errs := query.prepare()
+		if len(errs) > 0 {
+			t.Fatalf("with cte fail : %v", errs)
+		}
+		sql := query.parseSelectSQL(selectSQL, options)
+		if !strings.HasPrefix(sql, "WITH `paid` AS (SELECT `user_id` FROM b_order WHERE status = ? ") {
+			t.Fatalf("with cte sql fail : %s", sql)
+		}
+		if !reflect.DeepEqual(options.args(), []interface{}{"paid", 2}) {
+			t.Fatalf("with cte args fail : %v", options.args())
+		}
+		if err := categoryModel.WithCTE("paid; DROP", paid).Select(&[]struct{ ID int }{}); err == nil {
+			t.Fatalf("with cte name fail")
+		}
+	})
+}
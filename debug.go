@@ -0,0 +1,178 @@
+package mysqlgo
+
+import (
+	"database/sql/driver"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//DebugSQL 将最后执行的语句与参数合并为可直接在MySQL客户端执行的SQL，仅用于调试
+///不要用合并后的SQL执行语句，参数的转义按默认的sql_mode(未开启NO_BACKSLASH_ESCAPES)处理
+///合并失败时返回带有占位符的语句及参数
+func (m *Model) DebugSQL() string {
+	m.stateMu.Lock()
+	query, args := m.sql, append([]interface{}(nil), m.args...)
+	m.stateMu.Unlock()
+	sql, err := Interpolate(query, args)
+	if err != nil {
+		return fmt.Sprintf("%s -- args: %v", query, args)
+	}
+	return sql
+}
+
+//Interpolate 将参数按MySQL的转义规则替换语句中的占位符，仅用于调试
+///字符串转义后加单引号，[]byte为十六进制，time.Time为'2006-01-02 15:04:05.999999'，nil为NULL，bool为1或0
+///引号及注释(/* */、-- 、#)中的?不作为占位符
+func Interpolate(query string, args []interface{}) (string, error) {
+	positions := placeholderPositions(query)
+	if len(positions) > len(args) {
+		return "", fmt.Errorf("[Interpolate]: The number of placeholders is more than the args(%d)", len(args))
+	}
+	if len(positions) < len(args) {
+		return "", fmt.Errorf("[Interpolate]: The number of placeholders(%d) is less than the args(%d)", len(positions), len(args))
+	}
+	var b strings.Builder
+	last := 0
+	for n, pos := range positions {
+		value, err := interpolateValue(args[n])
+		if err != nil {
+			return "", fmt.Errorf("[Interpolate]: The arg %d : %w", n, err)
+		}
+		b.WriteString(query[last:pos])
+		b.WriteString(value)
+		last = pos + 1
+	}
+	b.WriteString(query[last:])
+	return b.String(), nil
+}
+
+//placeholderPositions 语句中占位符的位置，引号及注释中的?不是占位符
+func placeholderPositions(query string) []int {
+	var positions []int
+	var quote byte
+	for i := 0; i < len(query); i++ {
+		c := query[i]
+		if quote == 0 {
+			if end := commentEnd(query, i); end > i {
+				i = end - 1
+				continue
+			}
+		}
+		switch {
+		case quote != 0:
+			if c == '\\' && quote != '`' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '\'' || c == '"' || c == '`':
+			quote = c
+		case c == '?':
+			positions = append(positions, i)
+		}
+	}
+	return positions
+}
+
+//commentEnd 从i开始为注释时返回注释结束的位置，否则返回i
+func commentEnd(query string, i int) int {
+	rest := query[i:]
+	switch {
+	case strings.HasPrefix(rest, "/*"):
+		if end := strings.Index(rest[2:], "*/"); end >= 0 {
+			return i + 2 + end + 2
+		}
+		return len(query)
+	case strings.HasPrefix(rest, "#"), strings.HasPrefix(rest, "--") && (len(rest) == 2 || rest[2] == ' ' || rest[2] == '\t' || rest[2] == '\n'):
+		if end := strings.IndexByte(rest, '\n'); end >= 0 {
+			return i + end
+		}
+		return len(query)
+	}
+	return i
+}
+
+func interpolateValue(arg interface{}) (string, error) {
+	if valuer, ok := arg.(driver.Valuer); ok {
+		if v := reflect.ValueOf(arg); v.Kind() == reflect.Ptr && v.IsNil() {
+			return "NULL", nil
+		}
+		value, err := valuer.Value()
+		if err != nil {
+			return "", err
+		}
+		arg = value
+	}
+	switch v := arg.(type) {
+	case nil:
+		return "NULL", nil
+	case bool:
+		if v {
+			return "1", nil
+		}
+		return "0", nil
+	case string:
+		return "'" + escapeString(v) + "'", nil
+	case []byte:
+		if v == nil {
+			return "NULL", nil
+		}
+		return "X'" + hex.EncodeToString(v) + "'", nil
+	case time.Time:
+		if v.IsZero() {
+			return "'0000-00-00'", nil
+		}
+		return "'" + v.Format("2006-01-02 15:04:05.999999") + "'", nil
+	case float32:
+		return strconv.FormatFloat(float64(v), 'g', -1, 32), nil
+	case float64:
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+	rv := reflect.ValueOf(arg)
+	switch rv.Kind() {
+	case reflect.Ptr:
+		if rv.IsNil() {
+			return "NULL", nil
+		}
+		return interpolateValue(rv.Elem().Interface())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(rv.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(rv.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		return strconv.FormatFloat(rv.Float(), 'g', -1, 64), nil
+	case reflect.Bool:
+		return interpolateValue(rv.Bool())
+	case reflect.String:
+		return interpolateValue(rv.String())
+	}
+	return "", errors.New("The type " + rv.Type().String() + " is not supported")
+}
+
+//escapeString 按MySQL的规则转义字符串中的特殊字符
+func escapeString(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case 0:
+			b.WriteString(`\0`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\x1a':
+			b.WriteString(`\Z`)
+		case '\\', '\'', '"':
+			b.WriteByte('\\')
+			b.WriteByte(c)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,89 @@
+package mysqlgo
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestInterpolate(t *testing.T) {
+	name := "tom"
+	testCases := []struct {
+		name  string
+		query string
+		args  []interface{}
+		want  string
+	}{
+		{
+			name:  "string",
+			query: "SELECT * FROM b_user WHERE name = ? AND remark = ?",
+			args:  []interface{}{"it's", "a\\b\n\"c\""},
+			want:  `SELECT * FROM b_user WHERE name = 'it\'s' AND remark = 'a\\b\n\"c\"'`,
+		},
+		{
+			name:  "bytes",
+			query: "UPDATE b_user SET avatar = ?",
+			args:  []interface{}{[]byte{0x01, 0xab}},
+			want:  "UPDATE b_user SET avatar = X'01ab'",
+		},
+		{
+			name:  "time",
+			query: "SELECT * FROM b_user WHERE created_at > ?",
+			args:  []interface{}{time.Date(2024, 5, 1, 8, 30, 0, 120000000, time.UTC)},
+			want:  "SELECT * FROM b_user WHERE created_at > '2024-05-01 08:30:00.12'",
+		},
+		{
+			name:  "null and bool",
+			query: "UPDATE b_user SET deleted_at = ?, status = ?, nickname = ? WHERE id = ?",
+			args:  []interface{}{nil, true, &name, uint(3)},
+			want:  "UPDATE b_user SET deleted_at = NULL, status = 1, nickname = 'tom' WHERE id = 3",
+		},
+		{
+			name:  "quoted placeholder",
+			query: "SELECT * FROM b_user WHERE remark = 'why?' AND `a?` = ?",
+			args:  []interface{}{1.5},
+			want:  "SELECT * FROM b_user WHERE remark = 'why?' AND `a?` = 1.5",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			sql, err := Interpolate(tc.query, tc.args)
+			if err != nil {
+				t.Fatalf("interpolate fail : %v", err)
+			}
+			if sql != tc.want {
+				t.Fatalf("interpolate fail : %s", sql)
+			}
+		})
+	}
+
+	t.Run("args mismatch", func(t *testing.T) {
+		if _, err := Interpolate("SELECT * FROM b_user WHERE id = ?", nil); err == nil {
+			t.Fatalf("interpolate mismatch fail")
+		}
+		if _, err := Interpolate("SELECT * FROM b_user", []interface{}{1}); err == nil {
+			t.Fatalf("interpolate mismatch fail")
+		}
+	})
+
+	t.Run("comment", func(t *testing.T) {
+		query := (&Model{DBAlias: "debug", TableName: "b_user"}).FetchSQL().Comment("why? it's */ ok").Where("id = ?", 1)
+		query.Select(&[]struct{ ID int }{})
+		if sql := query.DebugSQL(); !strings.Contains(sql, "WHERE id = 1") || strings.Contains(sql, "-- args") {
+			t.Fatalf("debug comment fail : %s", sql)
+		}
+		sql, err := Interpolate("SELECT ? -- why?\n, ? # what?\n", []interface{}{1, 2})
+		if err != nil || sql != "SELECT 1 -- why?\n, 2 # what?\n" {
+			t.Fatalf("interpolate comment fail : %s, %v", sql, err)
+		}
+	})
+
+	t.Run("debug sql", func(t *testing.T) {
+		query := (&Model{DBAlias: "debug", TableName: "b_user"}).FetchSQL().Where("name = ?", "it's")
+		query.Delete()
+		if query.DebugSQL() != `DELETE FROM b_user WHERE name = 'it\'s'` {
+			t.Fatalf("debug sql fail : %s", query.DebugSQL())
+		}
+	})
+}
@@ -0,0 +1,140 @@
+package mysqlgo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+//错误类型，可配合errors.Is判断
+var (
+	ErrNoRows             = errors.New("no rows in result set")
+	ErrDuplicateKey       = errors.New("duplicate key")
+	ErrForeignKey         = errors.New("foreign key constraint fails")
+	ErrDeadlock           = errors.New("deadlock found when trying to get lock")
+	ErrAliasNotConfigured = errors.New("the database link is not configured")
+	ErrEmptyCondition     = errors.New("The Condition is null")
+	ErrStaleObject        = errors.New("the record has been modified by another operation")
+	ErrNotInTransaction   = errors.New("the statement must be executed in a transaction")
+	ErrLeaseLost          = errors.New("the job lease has expired or been taken by another consumer")
+	ErrTenantRequired     = errors.New("the tenant is required for a tenant-scoped table")
+	ErrShardKeyRequired   = errors.New("the shard key is required")
+	ErrInvalidIdentifier  = errors.New("the identifier is invalid")
+	ErrColumnNotAllowed   = errors.New("the column is not allowed")
+)
+
+//MySQL错误码
+const (
+	errCodeDupEntry        = 1062
+	errCodeDupEntryKeyName = 1586
+	errCodeDeadlock        = 1213
+	errCodeRowIsReferenced = 1451
+	errCodeNoReferencedRow = 1452
+	errCodeRowIsRefOld     = 1216
+	errCodeNoRefRowOld     = 1217
+)
+
+var dupEntryRegexp = regexp.MustCompile(`Duplicate entry '(.*)' for key '([^']+)'`)
+
+//ModelError 执行过程中出现的所有错误
+///可通过errors.Is/errors.As判断其中的任意一个错误
+type ModelError struct {
+	Errs []error
+}
+
+func (e *ModelError) Error() string {
+	var errs []string
+	for _, err := range e.Errs {
+		errs = append(errs, err.Error())
+	}
+	return fmt.Sprintf("\n[Model Error]:\n %s \n", strings.Join(errs, "\n"))
+}
+
+func (e *ModelError) Unwrap() []error {
+	return e.Errs
+}
+
+//DuplicateKeyError 唯一键冲突
+type DuplicateKeyError struct {
+	Key   string //冲突的索引名
+	Entry string //冲突的值
+	Err   *mysql.MySQLError
+}
+
+func (e *DuplicateKeyError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DuplicateKeyError) Is(target error) bool {
+	return target == ErrDuplicateKey
+}
+
+func (e *DuplicateKeyError) Unwrap() error {
+	return e.Err
+}
+
+//AliasError 数据库别名未配置
+type AliasError struct {
+	Alias string
+}
+
+func (e *AliasError) Error() string {
+	return fmt.Sprintf("[Config DB]: The database link `%s` is not configured", e.Alias)
+}
+
+func (e *AliasError) Is(target error) bool {
+	return target == ErrAliasNotConfigured
+}
+
+type classifiedError struct {
+	kind error
+	err  error
+}
+
+func (e *classifiedError) Error() string {
+	return e.err.Error()
+}
+
+func (e *classifiedError) Is(target error) bool {
+	return target == e.kind
+}
+
+func (e *classifiedError) Unwrap() error {
+	return e.err
+}
+
+//classifyError 按MySQL错误码将驱动错误归类
+func classifyError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, sql.ErrNoRows) {
+		return &classifiedError{kind: ErrNoRows, err: err}
+	}
+	var mysqlErr *mysql.MySQLError
+	if !errors.As(err, &mysqlErr) {
+		return err
+	}
+	switch mysqlErr.Number {
+	case errCodeDupEntry, errCodeDupEntryKeyName:
+		dup := &DuplicateKeyError{Err: mysqlErr}
+		if match := dupEntryRegexp.FindStringSubmatch(mysqlErr.Message); match != nil {
+			dup.Entry = match[1]
+			dup.Key = match[2]
+			//MySQL 8.0.19之后索引名带有表名前缀
+			if i := strings.LastIndex(dup.Key, "."); i > -1 {
+				dup.Key = dup.Key[i+1:]
+			}
+		}
+		return dup
+	case errCodeRowIsReferenced, errCodeNoReferencedRow, errCodeRowIsRefOld, errCodeNoRefRowOld:
+		return &classifiedError{kind: ErrForeignKey, err: err}
+	case errCodeDeadlock:
+		return &classifiedError{kind: ErrDeadlock, err: err}
+	}
+	return err
+}
@@ -0,0 +1,49 @@
+package mysqlgo
+
+import (
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/go-sql-driver/mysql"
+)
+
+func TestClassifyError(t *testing.T) {
+	t.Run("classify mysql error", func(t *testing.T) {
+		testCases := []struct {
+			in   error
+			kind error
+		}{
+			{in: sql.ErrNoRows, kind: ErrNoRows},
+			{in: &mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'test3' for key 'b_user.account'"}, kind: ErrDuplicateKey},
+			{in: &mysql.MySQLError{Number: 1452, Message: "Cannot add or update a child row"}, kind: ErrForeignKey},
+			{in: &mysql.MySQLError{Number: 1213, Message: "Deadlock found when trying to get lock"}, kind: ErrDeadlock},
+			{in: &AliasError{Alias: "slave"}, kind: ErrAliasNotConfigured},
+		}
+		for _, testCase := range testCases {
+			err := &ModelError{Errs: []error{classifyError(testCase.in)}}
+			if !errors.Is(err, testCase.kind) {
+				t.Fatalf("classify fail case : %v, err : %v", testCase, err)
+			}
+		}
+	})
+
+	t.Run("duplicate key name", func(t *testing.T) {
+		err := classifyError(&mysql.MySQLError{Number: 1062, Message: "Duplicate entry 'test3' for key 'b_user.account'"})
+		var dup *DuplicateKeyError
+		if !errors.As(err, &dup) || dup.Key != "account" || dup.Entry != "test3" {
+			t.Fatalf("duplicate key fail : %v", err)
+		}
+		var mysqlErr *mysql.MySQLError
+		if !errors.As(err, &mysqlErr) || mysqlErr.Number != 1062 {
+			t.Fatalf("unwrap mysql error fail : %v", err)
+		}
+	})
+
+	t.Run("empty condition", func(t *testing.T) {
+		userModel := &Model{TableName: "b_user"}
+		if _, err := userModel.Delete(); !errors.Is(err, ErrEmptyCondition) {
+			t.Fatalf("empty condition fail : %v", err)
+		}
+	})
+}
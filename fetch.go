@@ -0,0 +1,90 @@
+package mysqlgo
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"sync/atomic"
+)
+
+var dryRun atomic.Bool
+
+//SetDryRun 全局的预览模式，开启后所有Model只生成SQL不执行，不需要连接数据库
+///显式开启的事务(Begin、Transaction)仍然需要连接数据库
+func SetDryRun(enable bool) {
+	dryRun.Store(enable)
+}
+
+//FetchSQL 预览模式，Find、Select、Add、AddAll、Update、Delete及聚合方法只生成SQL不执行
+///生成的SQL和参数通过 LastSQL、LastArgs 获取，查询不会写入结果，修改返回的行数为0
+func (m *Model) FetchSQL() *Model {
+	q := m.clone()
+	q.options.fetchSQL = true
+	return q
+}
+
+//ToSQL 生成查询语句及参数，不执行
+func (m *Model) ToSQL() (string, []interface{}, error) {
+	if m.Sharding != nil {
+		targets, err := m.shardTargets("[Model ToSQL]")
+		if err != nil {
+			return "", nil, err
+		}
+		var sqls []string
+		var args []interface{}
+		for _, target := range targets {
+			s, a, err := target.ToSQL()
+			if err != nil {
+				return "", nil, err
+			}
+			sqls = append(sqls, s)
+			args = append(args, a...)
+		}
+		return strings.Join(sqls, ";\n"), args, nil
+	}
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return "", nil, &ModelError{Errs: errs}
+	}
+	m.scopeSoftDelete(options)
+	if err := m.scopeTenant(options); err != nil {
+		return "", nil, err
+	}
+	return m.parseSelectSQL(selectSQL, options), options.args(), nil
+}
+
+//LastArgs 最后执行的语句的参数
+func (m *Model) LastArgs() []interface{} {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	return append([]interface{}(nil), m.args...)
+}
+
+func (o *option) dryRun() bool {
+	return (o != nil && o.fetchSQL) || dryRun.Load()
+}
+
+//dryExecutor 预览模式下不执行语句
+type dryExecutor struct{}
+
+type dryResult struct{}
+
+func (dryExecutor) GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (dryExecutor) SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error {
+	return nil
+}
+
+func (dryExecutor) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	return dryResult{}, nil
+}
+
+func (dryResult) LastInsertId() (int64, error) {
+	return 0, nil
+}
+
+func (dryResult) RowsAffected() (int64, error) {
+	return 0, nil
+}
@@ -0,0 +1,124 @@
+package mysqlgo
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFetchSQL(t *testing.T) {
+	userModel := &Model{
+		DBAlias:   "fetch",
+		TableName: "b_user",
+	}
+
+	testCases := []struct {
+		name string
+		exec func(m *Model) (*Model, error)
+		sql  string
+		args []interface{}
+	}{
+		{
+			name: "find",
+			exec: func(m *Model) (*Model, error) {
+				m = m.Where("id = ?", 1)
+				return m, m.Find(&struct{ ID int }{})
+			},
+			sql:  "SELECT * FROM b_user WHERE id = ?  Limit 1  ",
+			args: []interface{}{1},
+		},
+		{
+			name: "add",
+			exec: func(m *Model) (*Model, error) {
+				_, err := m.Add(Data{Field: "name", Value: "tom"}, Data{Field: "age", Value: 18})
+				return m, err
+			},
+			sql:  "INSERT INTO b_user(`name`,`age`) VALUE(?,?)",
+			args: []interface{}{"tom", 18},
+		},
+		{
+			name: "update",
+			exec: func(m *Model) (*Model, error) {
+				m = m.Where("id = ?", 1)
+				_, err := m.Update(Data{Field: "name", Value: "tom"})
+				return m, err
+			},
+			sql:  "UPDATE b_user SET  `name` = ?  WHERE id = ?",
+			args: []interface{}{"tom", 1},
+		},
+		{
+			name: "delete",
+			exec: func(m *Model) (*Model, error) {
+				m = m.Where("id = ?", 1)
+				_, err := m.Delete()
+				return m, err
+			},
+			sql:  "DELETE FROM b_user WHERE id = ?",
+			args: []interface{}{1},
+		},
+		{
+			name: "count",
+			exec: func(m *Model) (*Model, error) {
+				m = m.Where("status = ?", 1)
+				_, err := m.Count()
+				return m, err
+			},
+			sql:  "SELECT COUNT(*) FROM b_user WHERE status = ?  ",
+			args: []interface{}{1},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			query, err := tc.exec(userModel.FetchSQL())
+			if err != nil {
+				t.Fatalf("fetch sql fail : %v", err)
+			}
+			if query.LastSQL() != tc.sql {
+				t.Fatalf("fetch sql fail : %s", query.LastSQL())
+			}
+			if !reflect.DeepEqual(query.LastArgs(), tc.args) {
+				t.Fatalf("fetch args fail : %v", query.LastArgs())
+			}
+		})
+	}
+
+	t.Run("optimistic", func(t *testing.T) {
+		query := (&Model{DBAlias: "fetch", TableName: "b_user", Version: "version"}).FetchSQL().Where("id = ?", 1)
+		if _, err := query.Update(Data{Field: "name", Value: "tom"}, Data{Field: "version", Value: 3}); err != nil {
+			t.Fatalf("fetch optimistic fail : %v", err)
+		}
+	})
+
+	t.Run("eager load", func(t *testing.T) {
+		orderModel := &Model{DBAlias: "fetch", TableName: "b_order"}
+		query := (&Model{DBAlias: "fetch", TableName: "b_user", Relations: map[string]*Relation{
+			"orders": {Type: HasMany, Model: orderModel, ForeignKey: "user_id"},
+		}}).FetchSQL().With("orders")
+		users := []orderUser{{ID: 1}}
+		if err := query.Select(&users); err != nil {
+			t.Fatalf("fetch eager load fail : %v", err)
+		}
+	})
+
+	t.Run("dry run", func(t *testing.T) {
+		SetDryRun(true)
+		defer SetDryRun(false)
+		query := userModel.Where("id = ?", 1)
+		if _, err := query.Delete(); err != nil {
+			t.Fatalf("dry run fail : %v", err)
+		}
+		if query.LastSQL() != "DELETE FROM b_user WHERE id = ?" {
+			t.Fatalf("dry run fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("to sql", func(t *testing.T) {
+		sql, args, err := userModel.Where("status = ?", 1).Order(Order{Field: "id", Desc: true}).ToSQL()
+		if err != nil {
+			t.Fatalf("to sql fail : %v", err)
+		}
+		if sql != "SELECT * FROM b_user WHERE status = ?  ORDER BY  `id` desc  " || !reflect.DeepEqual(args, []interface{}{1}) {
+			t.Fatalf("to sql fail : %s %v", sql, args)
+		}
+	})
+}
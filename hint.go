@@ -0,0 +1,144 @@
+package mysqlgo
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+var optimizerHint = regexp.MustCompile(`^[A-Za-z_]+\s*\([^()*;]*\)$`)
+
+//ForceIndex 强制主表使用指定的索引
+func (m *Model) ForceIndex(indexes ...string) *Model {
+	return m.indexHint("[Model ForceIndex]", "FORCE", indexes)
+}
+
+//UseIndex 建议主表使用指定的索引
+func (m *Model) UseIndex(indexes ...string) *Model {
+	return m.indexHint("[Model UseIndex]", "USE", indexes)
+}
+
+//IgnoreIndex 主表忽略指定的索引
+func (m *Model) IgnoreIndex(indexes ...string) *Model {
+	return m.indexHint("[Model IgnoreIndex]", "IGNORE", indexes)
+}
+
+func (m *Model) indexHint(method string, kind string, indexes []string) *Model {
+	q := m.clone()
+	hint, err := parseIndexHint(kind, indexes)
+	if err != nil {
+		q.options.err = append(q.options.err, fmt.Errorf("%s: %w", method, err))
+		return q
+	}
+	q.options.indexHints = append(q.options.indexHints, hint)
+	return q
+}
+
+//ForceIndex 强制联表使用指定的索引
+func (j Join) ForceIndex(indexes ...string) Join {
+	return j.indexHint("FORCE", indexes)
+}
+
+//UseIndex 建议联表使用指定的索引
+func (j Join) UseIndex(indexes ...string) Join {
+	return j.indexHint("USE", indexes)
+}
+
+//IgnoreIndex 联表忽略指定的索引
+func (j Join) IgnoreIndex(indexes ...string) Join {
+	return j.indexHint("IGNORE", indexes)
+}
+
+func (j Join) indexHint(kind string, indexes []string) Join {
+	j = j.copy()
+	if j.err != nil {
+		return j
+	}
+	if !j.built || j.Sub != nil {
+		j.err = errors.New("The index hint is only supported on the table joined by InnerJoin, LeftJoin, etc.")
+		return j
+	}
+	hint, err := parseIndexHint(kind, indexes)
+	if err != nil {
+		j.err = err
+		return j
+	}
+	j.hints = append(j.hints, hint)
+	return j
+}
+
+func parseIndexHint(kind string, indexes []string) (string, error) {
+	if len(indexes) == 0 {
+		return "", fmt.Errorf("The indexes of %s INDEX is null", kind)
+	}
+	quoted := make([]string, 0, len(indexes))
+	for _, index := range indexes {
+		if !identifierPart.MatchString(index) {
+			return "", fmt.Errorf("%s : %w", index, ErrInvalidIdentifier)
+		}
+		quoted = append(quoted, "`"+strings.Trim(index, "`")+"`")
+	}
+	return fmt.Sprintf("%s INDEX (%s)", kind, strings.Join(quoted, ",")), nil
+}
+
+//Hint 添加优化器提示(MySQL 8)，生成 SELECT /*+ ... */
+///如 Hint(MaxExecutionTime(1000), SetVar("sort_buffer_size", "16M"), JoinOrder("o", "u"))
+func (m *Model) Hint(hints ...string) *Model {
+	q := m.clone()
+	for _, hint := range hints {
+		hint = strings.TrimSpace(hint)
+		if !optimizerHint.MatchString(hint) {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Hint]: The hint `%s` is invalid", hint))
+			continue
+		}
+		q.options.hints = append(q.options.hints, hint)
+	}
+	return q
+}
+
+//MaxExecutionTime 查询的最长执行时间，单位毫秒
+func MaxExecutionTime(ms int) string {
+	return fmt.Sprintf("MAX_EXECUTION_TIME(%d)", ms)
+}
+
+//SetVar 在本次查询中修改系统变量
+func SetVar(name string, value interface{}) string {
+	return fmt.Sprintf("SET_VAR(%s = %v)", name, value)
+}
+
+//JoinOrder 按指定的顺序联表
+func JoinOrder(tables ...string) string {
+	return fmt.Sprintf("JOIN_ORDER(%s)", strings.Join(tables, ", "))
+}
+
+//JoinPrefix 指定最先联表的表
+func JoinPrefix(tables ...string) string {
+	return fmt.Sprintf("JOIN_PREFIX(%s)", strings.Join(tables, ", "))
+}
+
+//JoinSuffix 指定最后联表的表
+func JoinSuffix(tables ...string) string {
+	return fmt.Sprintf("JOIN_SUFFIX(%s)", strings.Join(tables, ", "))
+}
+
+func (m *Model) parseHint(hints ...string) string {
+	if len(hints) == 0 {
+		return ""
+	}
+	return fmt.Sprintf(" /*+ %s */", strings.Join(hints, " "))
+}
+
+//parseFrom 生成FROM的数据表，索引提示作用于主表，主表为子查询时在prepare中返回错误
+func (m *Model) parseFrom(options *option) string {
+	if len(options.indexHints) == 0 {
+		return m.parseTable(options.table...)
+	}
+	hint := strings.Join(options.indexHints, " ")
+	if len(options.table) == 0 {
+		return fmt.Sprintf("%s %s", m.getTableName(), hint)
+	}
+	tables := append([]Table(nil), options.table...)
+	tables[0].hint = hint
+	return m.parseTable(tables...)
+}
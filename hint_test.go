@@ -0,0 +1,54 @@
+package mysqlgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHint(t *testing.T) {
+	userModel := &Model{
+		TableName: "b_user",
+	}
+
+	t.Run("index hint", func(t *testing.T) {
+		testCases := []struct {
+			query *Model
+			sql   string
+		}{
+			{query: userModel.ForceIndex("idx_status"), sql: "FROM b_user FORCE INDEX (`idx_status`) "},
+			{query: userModel.Table(Table{Name: "b_user", Alias: "u"}, Table{Name: "b_role", Alias: "r"}).UseIndex("idx_a", "idx_b").IgnoreIndex("PRIMARY"),
+				sql: "FROM  `b_user` u USE INDEX (`idx_a`,`idx_b`) IGNORE INDEX (`PRIMARY`), `b_role` r "},
+			{query: userModel.Join(LeftJoin("b_order", "o").ForceIndex("idx_user").On("o.user_id", "=", "b_user.id")),
+				sql: "LEFT JOIN `b_order` o FORCE INDEX (`idx_user`) ON `o`.`user_id` = `b_user`.`id`"},
+		}
+		for _, testCase := range testCases {
+			testCase.query.Select(&[]struct{ ID int }{})
+			if !strings.Contains(testCase.query.LastSQL(), testCase.sql) {
+				t.Fatalf("index hint fail case : %s, sql : %s", testCase.sql, testCase.query.LastSQL())
+			}
+		}
+		invalids := []*Model{
+			userModel.ForceIndex(),
+			userModel.UseIndex("idx) UNION SELECT"),
+			userModel.Join(Join{Statement: "b_order o ON o.user_id = b_user.id"}.ForceIndex("idx_user")),
+			userModel.Table(Table{Sub: (&Model{TableName: "b_order"}).Field("user_id"), Alias: "t"}).ForceIndex("idx_user"),
+		}
+		for i, query := range invalids {
+			if err := query.Select(&[]struct{ ID int }{}); err == nil {
+				t.Fatalf("index hint validate fail case : %d", i)
+			}
+		}
+	})
+
+	t.Run("optimizer hint", func(t *testing.T) {
+		query := userModel.Hint(MaxExecutionTime(1000), SetVar("sort_buffer_size", "16M"), JoinOrder("o", "u")).Where("id = ?", 1)
+		query.Select(&[]struct{ ID int }{})
+		want := "SELECT /*+ MAX_EXECUTION_TIME(1000) SET_VAR(sort_buffer_size = 16M) JOIN_ORDER(o, u) */ * FROM b_user"
+		if !strings.HasPrefix(query.LastSQL(), want) {
+			t.Fatalf("optimizer hint fail : %s", query.LastSQL())
+		}
+		if err := userModel.Hint("BKA(t) */ DROP TABLE b_user; /*").Select(&[]struct{ ID int }{}); err == nil {
+			t.Fatalf("optimizer hint validate fail")
+		}
+	})
+}
@@ -0,0 +1,181 @@
+package mysqlgo
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strings"
+)
+
+var identifierPart = regexp.MustCompile("^(`[^`]+`|[A-Za-z_][A-Za-z0-9_$]*)$")
+
+//quoteIdentifier 校验标识符并加上反引号，支持 column、table.column、db.table.column
+///不符合规则的表达式需要使用 FieldRaw、OrderRaw、GroupRaw 原样写入
+func quoteIdentifier(name string) (string, error) {
+	parts := strings.Split(strings.TrimSpace(name), ".")
+	if len(parts) > 3 {
+		return "", fmt.Errorf("%s : %w", name, ErrInvalidIdentifier)
+	}
+	for i, part := range parts {
+		if !identifierPart.MatchString(part) {
+			return "", fmt.Errorf("%s : %w", name, ErrInvalidIdentifier)
+		}
+		if !strings.HasPrefix(part, "`") {
+			parts[i] = "`" + part + "`"
+		}
+	}
+	return strings.Join(parts, "."), nil
+}
+
+//quoteField 校验查询的字段，支持 *、table.*、column AS alias
+func quoteField(field string) (string, error) {
+	words := strings.Fields(field)
+	if len(words) == 3 && strings.EqualFold(words[1], "AS") {
+		words = []string{words[0], words[2]}
+	}
+	if len(words) == 0 || len(words) > 2 {
+		return "", fmt.Errorf("%s : %w", field, ErrInvalidIdentifier)
+	}
+	var expr string
+	var err error
+	switch {
+	case words[0] == "*":
+		expr = "*"
+	case strings.HasSuffix(words[0], ".*"):
+		expr, err = quoteIdentifier(strings.TrimSuffix(words[0], ".*"))
+		expr += ".*"
+	default:
+		expr, err = quoteIdentifier(words[0])
+	}
+	if err != nil {
+		return "", fmt.Errorf("%s : %w", field, ErrInvalidIdentifier)
+	}
+	if len(words) == 1 {
+		return expr, nil
+	}
+	alias, err := quoteIdentifier(words[1])
+	if err != nil || strings.Contains(alias, ".") {
+		return "", fmt.Errorf("%s : %w", field, ErrInvalidIdentifier)
+	}
+	return fmt.Sprintf("%s AS %s", expr, alias), nil
+}
+
+//quoteFields 校验写入数据的字段
+func quoteFields(fields []string) ([]string, error) {
+	quoted := make([]string, 0, len(fields))
+	for _, field := range fields {
+		q, err := quoteIdentifier(field)
+		if err != nil {
+			return nil, err
+		}
+		quoted = append(quoted, q)
+	}
+	return quoted, nil
+}
+
+//quoteAggregate 校验聚合函数的字段，支持 * 和 DISTINCT column
+func quoteAggregate(field string) (string, error) {
+	if field == "*" {
+		return field, nil
+	}
+	words := strings.Fields(field)
+	if len(words) == 2 && strings.EqualFold(words[0], "DISTINCT") {
+		q, err := quoteIdentifier(words[1])
+		return "DISTINCT " + q, err
+	}
+	return quoteIdentifier(field)
+}
+
+//allowColumn 字段是否在允许的列表中，列表为空时不限制
+func allowColumn(columns []string, name string) bool {
+	if len(columns) == 0 {
+		return true
+	}
+	name = strings.Replace(name, "`", "", -1)
+	column := name[strings.LastIndex(name, ".")+1:]
+	for _, c := range columns {
+		if c == name || c == column {
+			return true
+		}
+	}
+	return false
+}
+
+//FieldRaw 追加原样写入的查询字段，如 FieldRaw("COUNT(*) AS num")
+///不会校验表达式，不能包含外部输入
+func (m *Model) FieldRaw(fields ...Raw) *Model {
+	q := m.clone()
+	for _, field := range fields {
+		q.options.rawFields = append(q.options.rawFields, string(field))
+	}
+	return q
+}
+
+//OrderRaw 原样写入的排序表达式，包含排序方式，如 OrderRaw("FIELD(status, 2, 1) DESC")
+///不会校验表达式，不能包含外部输入
+func (m *Model) OrderRaw(order Raw) *Model {
+	q := m.clone()
+	q.options.order = append(q.options.order, Order{Field: string(order), raw: true})
+	return q
+}
+
+//GroupRaw 原样写入的分组表达式，如 GroupRaw("DATE(created_at)")
+///不会校验表达式，不能包含外部输入
+func (m *Model) GroupRaw(groups ...Raw) *Model {
+	q := m.clone()
+	for _, group := range groups {
+		q.options.group = append(q.options.group, string(group))
+	}
+	return q
+}
+
+//Filter 按字段过滤，op为 EQ、NEQ、GT、EGT、LT、ELT、LIKE、NLIKE、IN、NOTIN、BETWEEN、NBETWEEN
+///字段会被校验，设置了Filterable时只能使用其中的字段，适用于字段来自外部输入的场景
+///IN、NOTIN 的value为切片，BETWEEN、NBETWEEN 的value为两个元素的切片
+func (m *Model) Filter(field string, op string, value interface{}) *Model {
+	q := m.clone()
+	column, err := quoteIdentifier(field)
+	if err != nil {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Filter]: %w", err))
+		return q
+	}
+	if !allowColumn(m.Filterable, field) {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Filter]: %s : %w", field, ErrColumnNotAllowed))
+		return q
+	}
+	op = strings.ToUpper(op)
+	operator, ok := exp[op]
+	if !ok {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Filter]: The operator `%s` is invalid", op))
+		return q
+	}
+	args := []interface{}{value}
+	mark := "?"
+	switch op {
+	case "IN", "NOTIN", "BETWEEN", "NBETWEEN":
+		rv := reflect.ValueOf(value)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Filter]: The value of `%s` must be a slice", op))
+			return q
+		}
+		args = nil
+		for i := 0; i < rv.Len(); i++ {
+			args = append(args, rv.Index(i).Interface())
+		}
+		if op == "BETWEEN" || op == "NBETWEEN" {
+			if len(args) != 2 {
+				q.options.err = append(q.options.err, fmt.Errorf("[Model Filter]: The value of `%s` must have two elements", op))
+				return q
+			}
+			mark = "? AND ?"
+		} else {
+			if len(args) == 0 {
+				q.options.err = append(q.options.err, fmt.Errorf("[Model Filter]: The value of `%s` is null", op))
+				return q
+			}
+			mark = fmt.Sprintf("(%s)", placeholders(len(args)))
+		}
+	}
+	q.options.and(fmt.Sprintf("%s %s %s", column, operator, mark), args...)
+	return q
+}
@@ -0,0 +1,81 @@
+package mysqlgo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestIdentifier(t *testing.T) {
+	t.Run("quote field", func(t *testing.T) {
+		testCases := []struct {
+			field  string
+			quoted string
+			ok     bool
+		}{
+			{field: "id", quoted: "`id`", ok: true},
+			{field: "u.account", quoted: "`u`.`account`", ok: true},
+			{field: "u.*", quoted: "`u`.*", ok: true},
+			{field: "user_id AS uid", quoted: "`user_id` AS `uid`", ok: true},
+			{field: "`order`", quoted: "`order`", ok: true},
+			{field: "id; DROP TABLE b_user", ok: false},
+			{field: "(SELECT 1)", ok: false},
+			{field: "id` = 1 --", ok: false},
+			{field: "a.b.c.d", ok: false},
+		}
+		for _, testCase := range testCases {
+			quoted, err := quoteField(testCase.field)
+			if (err == nil) != testCase.ok || quoted != testCase.quoted {
+				t.Fatalf("quote field fail case : %v, quoted : %s, err : %v", testCase, quoted, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidIdentifier) {
+				t.Fatalf("quote field error fail : %v", err)
+			}
+		}
+	})
+
+	t.Run("builder", func(t *testing.T) {
+		userModel := &Model{
+			TableName:  "b_user",
+			Sortable:   []string{"id", "created_at"},
+			Filterable: []string{"status"},
+		}
+		query := userModel.Field("id", "account").FieldRaw("COUNT(*) AS num").
+			Filter("status", "in", []int{1, 2}).
+			Group("account").
+			Order(Order{Field: "u.created_at", Desc: true}).
+			OrderRaw("FIELD(status, 2, 1)")
+		query.Select(&[]struct{ ID int }{})
+		want := "SELECT `id`,`account`,COUNT(*) AS num FROM b_user WHERE `status` IN (?,?)  GROUP BY `account` ORDER BY  `u`.`created_at` desc, FIELD(status, 2, 1)"
+		if !strings.Contains(query.LastSQL(), want) {
+			t.Fatalf("builder fail : %s", query.LastSQL())
+		}
+
+		testCases := []*Model{
+			userModel.Order(Order{Field: "account"}),
+			userModel.Order(Order{Field: "id desc"}),
+			userModel.Filter("account", "eq", "test"),
+			userModel.Filter("status", "regexp", "1"),
+			userModel.Group("DATE(created_at)"),
+			userModel.Table(Table{Name: "b_user u, b_order"}),
+		}
+		for i, testCase := range testCases {
+			if err := testCase.Select(&[]struct{ ID int }{}); err == nil {
+				t.Fatalf("builder validate fail case : %d, sql : %s", i, testCase.LastSQL())
+			}
+		}
+		if err := userModel.Order(Order{Field: "account"}).Select(&[]struct{ ID int }{}); !errors.Is(err, ErrColumnNotAllowed) {
+			t.Fatalf("sortable fail : %v", err)
+		}
+		for _, op := range []string{"EGT", "ngt"} {
+			query = userModel.Filter("status", op, 3)
+			query.Select(&[]struct{ ID int }{})
+			if !strings.Contains(query.LastSQL(), "WHERE `status` >= ?") {
+				t.Fatalf("filter %s fail : %s", op, query.LastSQL())
+			}
+		}
+		if _, err := userModel.Where("id = ?", 1).Update(Data{Field: "name = 'x', role", Value: 1}); !errors.Is(err, ErrInvalidIdentifier) {
+			t.Fatalf("update field fail : %v", err)
+		}
+	})
+}
@@ -0,0 +1,192 @@
+package mysqlgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//联表类型
+const (
+	JoinInner    = iota //INNER JOIN
+	JoinLeft            //LEFT JOIN
+	JoinRight           //RIGHT JOIN
+	JoinFull            //FULL OUTER JOIN，MySQL不支持，通过 LEFT JOIN UNION RIGHT JOIN 模拟
+	JoinCross           //CROSS JOIN
+	JoinStraight        //STRAIGHT_JOIN，按书写顺序联表
+)
+
+var joinKeywords = map[int]string{
+	JoinInner:    "INNER JOIN",
+	JoinLeft:     "LEFT JOIN",
+	JoinRight:    "RIGHT JOIN",
+	JoinFull:     "FULL JOIN",
+	JoinCross:    "CROSS JOIN",
+	JoinStraight: "STRAIGHT_JOIN",
+}
+
+//joinedStatement 以JOIN子句开头的语句不再添加联表关键字
+var joinedStatement = regexp.MustCompile(`(?i)^\s*(((NATURAL|LEFT|RIGHT|INNER|CROSS|OUTER)\s+)*JOIN|STRAIGHT_JOIN)\b`)
+
+var joinOperators = map[string]bool{
+	"=": true, "<>": true, "!=": true, "<": true, "<=": true, ">": true, ">=": true, "<=>": true,
+}
+
+//joinCondition ON条件
+type joinCondition struct {
+	or   bool
+	expr string
+}
+
+//InnerJoin 内联表，如 Join(InnerJoin("b_order", "o").On("o.user_id", "=", "b_user.id"))
+func InnerJoin(table string, alias string) Join {
+	return newJoin(JoinInner, table, alias)
+}
+
+//LeftJoin 左联表
+func LeftJoin(table string, alias string) Join {
+	return newJoin(JoinLeft, table, alias)
+}
+
+//RightJoin 右联表
+func RightJoin(table string, alias string) Join {
+	return newJoin(JoinRight, table, alias)
+}
+
+//FullJoin 全联表，通过 LEFT JOIN 与 RIGHT JOIN 的查询 UNION 模拟
+///UNION 会去除重复的行，结果中完全相同的行只保留一行
+func FullJoin(table string, alias string) Join {
+	return newJoin(JoinFull, table, alias)
+}
+
+//CrossJoin 笛卡尔积联表
+func CrossJoin(table string, alias string) Join {
+	return newJoin(JoinCross, table, alias)
+}
+
+//StraightJoin 按书写顺序联表，强制左表为驱动表
+func StraightJoin(table string, alias string) Join {
+	return newJoin(JoinStraight, table, alias)
+}
+
+//JoinSub 与子查询内联，联表类型可以通过Type修改
+func JoinSub(sub *Model, alias string) Join {
+	j := Join{Type: JoinInner, Sub: sub, Alias: alias, built: true}
+	if sub == nil || !identifierPart.MatchString(alias) {
+		j.err = fmt.Errorf("The alias of subquery is required : %w", ErrInvalidIdentifier)
+	}
+	return j
+}
+
+func newJoin(typ int, table string, alias string) Join {
+	j := Join{Type: typ, table: table, Alias: alias, built: true}
+	if _, err := quoteIdentifier(table); err != nil {
+		j.err = err
+	} else if alias != "" && !identifierPart.MatchString(alias) {
+		j.err = fmt.Errorf("%s : %w", alias, ErrInvalidIdentifier)
+	}
+	return j
+}
+
+//On 字段之间的ON条件，多个条件之间为AND
+func (j Join) On(left string, op string, right string) Join {
+	return j.on(false, left, op, right)
+}
+
+//OrOn 字段之间的ON条件，与之前的条件之间为OR
+func (j Join) OrOn(left string, op string, right string) Join {
+	return j.on(true, left, op, right)
+}
+
+//OnValue 字段与参数之间的ON条件，如 OnValue("o.status", "=", "paid")
+func (j Join) OnValue(field string, op string, value interface{}) Join {
+	j = j.copy()
+	column, err := j.condition(field, op)
+	if err != nil {
+		return j
+	}
+	j.conditions = append(j.conditions, joinCondition{expr: fmt.Sprintf("%s %s ?", column, op)})
+	j.args = append(j.args, value)
+	return j
+}
+
+func (j Join) on(or bool, left string, op string, right string) Join {
+	j = j.copy()
+	column, err := j.condition(left, op)
+	if err != nil {
+		return j
+	}
+	value, err := quoteIdentifier(right)
+	if err != nil {
+		j.err = err
+		return j
+	}
+	j.conditions = append(j.conditions, joinCondition{or: or, expr: fmt.Sprintf("%s %s %s", column, op, value)})
+	return j
+}
+
+func (j *Join) condition(field string, op string) (string, error) {
+	if j.err != nil {
+		return "", j.err
+	}
+	if !joinOperators[op] {
+		j.err = fmt.Errorf("The operator `%s` is invalid", op)
+		return "", j.err
+	}
+	column, err := quoteIdentifier(field)
+	if err != nil {
+		j.err = err
+	}
+	return column, err
+}
+
+//copy Join按值传递，追加条件前复制切片，避免共用底层数组
+func (j Join) copy() Join {
+	j.conditions = append([]joinCondition(nil), j.conditions...)
+	j.args = append([]interface{}(nil), j.args...)
+	j.hints = append([]string(nil), j.hints...)
+	return j
+}
+
+func (j Join) parseOn() string {
+	var b strings.Builder
+	for i, c := range j.conditions {
+		if i > 0 {
+			if c.or {
+				b.WriteString(" OR ")
+			} else {
+				b.WriteString(" AND ")
+			}
+		}
+		b.WriteString(c.expr)
+	}
+	return b.String()
+}
+
+//fullJoin 将 FULL JOIN 改写为 LEFT JOIN 的查询 UNION RIGHT JOIN 的查询
+func (m *Model) fullJoin(options *option) {
+	full := false
+	for _, join := range options.join {
+		if join.Type == JoinFull {
+			full = true
+		}
+	}
+	if !full {
+		return
+	}
+	right := m.clone()
+	right.GlobalScopes = nil
+	right.options = options.clone()
+	right.options.ctes = nil
+	right.options.unions = nil
+	right.options.order = nil
+	right.options.limit = Limit{}
+	right.options.comment = ""
+	for i := range options.join {
+		if options.join[i].Type == JoinFull {
+			options.join[i].Type = JoinLeft
+			right.options.join[i].Type = JoinRight
+		}
+	}
+	options.unions = append([]unionPart{{query: right}}, options.unions...)
+}
@@ -0,0 +1,81 @@
+package mysqlgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJoin(t *testing.T) {
+	userModel := &Model{
+		TableName: "b_user",
+	}
+
+	t.Run("join builder", func(t *testing.T) {
+		paid := (&Model{TableName: "b_order"}).Field("user_id").Where("status = ?", "paid")
+		query := userModel.Field("b_user.id").
+			Join(LeftJoin("b_order", "o").On("o.user_id", "=", "b_user.id").OnValue("o.amount", ">", 100).OrOn("o.parent_id", "=", "b_user.id")).
+			Join(JoinSub(paid, "p").On("p.user_id", "=", "b_user.id")).
+			Join(CrossJoin("b_region", "r")).
+			Join(StraightJoin("b_level", "l").On("l.id", "=", "b_user.level_id")).
+			Join(Join{Statement: "b_role ro ON ro.id = b_user.role_id", Type: JoinRight}).
+			Join(Join{Statement: "b_login lg ON lg.joined_at > b_user.created_at"}).
+			Join(Join{Statement: "left outer join b_tag tg ON tg.user_id = b_user.id"}).
+			Join(Join{Statement: "STRAIGHT_JOIN b_team tm ON tm.id = b_user.team_id"}).
+			Where("b_user.status = ?", 1)
+		options, errs := query.prepare()
+		if len(errs) > 0 {
+			t.Fatalf("join builder fail : %v", errs)
+		}
+		want := " LEFT JOIN `b_order` o ON `o`.`user_id` = `b_user`.`id` AND `o`.`amount` > ? OR `o`.`parent_id` = `b_user`.`id`" +
+			" INNER JOIN (SELECT `user_id` FROM b_order WHERE status = ?  ) p ON `p`.`user_id` = `b_user`.`id`" +
+			" CROSS JOIN `b_region` r" +
+			" STRAIGHT_JOIN `b_level` l ON `l`.`id` = `b_user`.`level_id`" +
+			" RIGHT JOIN b_role ro ON ro.id = b_user.role_id" +
+			" INNER JOIN b_login lg ON lg.joined_at > b_user.created_at" +
+			" left outer join b_tag tg ON tg.user_id = b_user.id" +
+			" STRAIGHT_JOIN b_team tm ON tm.id = b_user.team_id "
+		if sql := query.parseJoin(options.join...); sql != want {
+			t.Fatalf("join builder sql fail : %s", sql)
+		}
+		if !reflect.DeepEqual(options.args(), []interface{}{100, "paid", 1}) {
+			t.Fatalf("join builder args fail : %v", options.args())
+		}
+
+		invalids := []Join{
+			InnerJoin("b_order; DROP", "o"),
+			InnerJoin("b_order", "o").On("o.user_id", "= 1 OR", "b_user.id"),
+			InnerJoin("b_order", "o").On("o.user_id", "=", "1 OR 1"),
+			JoinSub(paid, ""),
+		}
+		for i, join := range invalids {
+			if err := userModel.Join(join).Select(&[]struct{ ID int }{}); err == nil {
+				t.Fatalf("join validate fail case : %d", i)
+			}
+		}
+	})
+
+	t.Run("full join", func(t *testing.T) {
+		query := userModel.Field("b_user.id", "o.id AS order_id").
+			Join(FullJoin("b_order", "o").On("o.user_id", "=", "b_user.id").OnValue("o.status", "=", "paid")).
+			Where("b_user.level = ?", 2).
+			Order(Order{Field: "order_id"})
+		options, errs := query.prepare()
+		if len(errs) > 0 {
+			t.Fatalf("full join fail : %v", errs)
+		}
+		sql := query.parseSelectSQL(selectSQL, options)
+		for _, part := range []string{
+			"SELECT * FROM (SELECT `b_user`.`id`,`o`.`id` AS `order_id` FROM b_user LEFT JOIN `b_order` o ON ",
+			" UNION (SELECT `b_user`.`id`,`o`.`id` AS `order_id` FROM b_user RIGHT JOIN `b_order` o ON ",
+			") AS `union_result` ORDER BY  `order_id` asc",
+		} {
+			if !strings.Contains(sql, part) {
+				t.Fatalf("full join sql fail : %s, sql : %s", part, sql)
+			}
+		}
+		if !reflect.DeepEqual(options.args(), []interface{}{"paid", 2, "paid", 2}) {
+			t.Fatalf("full join args fail : %v", options.args())
+		}
+	})
+}
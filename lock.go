@@ -0,0 +1,36 @@
+package mysqlgo
+
+//加锁时遇到已被锁定的行的处理方式
+const (
+	LockWait       = iota //等待锁释放
+	LockNoWait            //NOWAIT: 立即返回错误
+	LockSkipLocked        //SKIP LOCKED: 跳过已被锁定的行
+)
+
+//LockForUpdate 查询时加排他锁(FOR UPDATE)，只能在事务中使用
+func (m *Model) LockForUpdate(wait ...int) *Model {
+	q := m.clone()
+	q.options.lock = " FOR UPDATE" + parseLockWait(wait...)
+	return q
+}
+
+//SharedLock 查询时加共享锁(FOR SHARE)，只能在事务中使用
+func (m *Model) SharedLock(wait ...int) *Model {
+	q := m.clone()
+	q.options.lock = " FOR SHARE" + parseLockWait(wait...)
+	return q
+}
+
+func parseLockWait(wait ...int) string {
+	if len(wait) == 0 {
+		return ""
+	}
+	switch wait[0] {
+	case LockNoWait:
+		return " NOWAIT"
+	case LockSkipLocked:
+		return " SKIP LOCKED"
+	default:
+		return ""
+	}
+}
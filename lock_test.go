@@ -0,0 +1,40 @@
+package mysqlgo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestLock(t *testing.T) {
+	t.Run("lock clause", func(t *testing.T) {
+		jobModel := &Model{
+			TableName: "b_job",
+		}
+		testCases := []struct {
+			in   *Model
+			want string
+		}{
+			{in: jobModel.LockForUpdate(), want: "FROM b_job FOR UPDATE"},
+			{in: jobModel.LockForUpdate(LockSkipLocked), want: "FROM b_job FOR UPDATE SKIP LOCKED"},
+			{in: jobModel.SharedLock(LockNoWait), want: "FROM b_job FOR SHARE NOWAIT"},
+		}
+		for _, testCase := range testCases {
+			options, _ := testCase.in.prepare()
+			sql := testCase.in.parseSelectSQL(selectSQL, options)
+			if !strings.Contains(sql, testCase.want) {
+				t.Fatalf("lock clause fail case : %s, sql : %s", testCase.want, sql)
+			}
+		}
+	})
+
+	t.Run("lock outside transaction", func(t *testing.T) {
+		jobModel := &Model{
+			TableName: "b_job",
+		}
+		var jobs []struct{ ID int64 }
+		if err := jobModel.LockForUpdate().Select(&jobs); !errors.Is(err, ErrNotInTransaction) {
+			t.Fatalf("lock outside transaction fail : %v", err)
+		}
+	})
+}
@@ -1,8 +1,11 @@
 package mysqlgo
 
 import (
+	"context"
+	"database/sql"
 	"errors"
 	"fmt"
+	"regexp"
 	"strings"
 	"sync"
 )
@@ -11,23 +14,38 @@ import (
 type Model struct{
 	DBAlias		string
 	TableName 	string
-	Prefix		string
-	err			[]string
+	Prefix		string		//数据表前缀，为空时使用数据库配置中的Prefix
+	SoftDelete	*SoftDelete
+	Timestamps	*Timestamps
+	Version		string
+	Relations	map[string]*Relation
+	Scopes		map[string]Scope
+	GlobalScopes	map[string]Scope
+	TenantColumn	string
+	Sharding	*Sharding
+	Sortable	[]string	//允许排序的字段，为空时不限制
+	Filterable	[]string	//允许Filter过滤的字段，为空时不限制
+	err			[]error
 	sql			string
+	args		[]interface{}
 	options		*option
-	initLock	sync.RWMutex
+	stateMu		sync.Mutex
 }
 
 //Table 数据表
 type Table struct {
 	Name 	string	//表名
 	Alias	string	//别名
+	Sub		*Model	//子查询，使用子查询时必须指定别名
+	sql		string
+	hint	string
 }
 
 //Order 结果排序
 type Order struct {
 	Field 	string 	//排序字段
 	Desc	bool	//排序方式: 默认false为asc排序，true为desc排序
+	raw		bool
 }
 
 //Limit 查询和操作的记录数量
@@ -42,16 +60,22 @@ type Limit struct {
 ///0为INNER JOIN: 如果表中有至少一个匹配，则返回行，等同于 JOIN
 ///1为LEFT JOIN: 即使右表中没有匹配，也从左表返回所有的行
 ///2为RIGHT JOIN: 即使左表中没有匹配，也从右表返回所有的行
-///3为FULL JOIN: 只要其中一个表中存在匹配，就返回行
+///3为FULL JOIN: 只要其中一个表中存在匹配，就返回行，通过 LEFT JOIN UNION RIGHT JOIN 模拟
+///4为CROSS JOIN，5为STRAIGHT_JOIN
+///如 Join{Sub: orderModel.Group("user_id"), Alias: "o", Statement: "o.user_id = b_user.id"}
+///ON条件需要绑定参数时使用 InnerJoin、LeftJoin 等构建，如 InnerJoin("b_order", "o").On("o.user_id", "=", "b_user.id")
 type Join struct {
 	Statement 	string	
 	Type		int		
-}
-
-//Union 合并Select
-type Union struct {
-	SelectSQL 	[]string
-	All			bool 
+	Sub			*Model	//子查询，使用子查询时Statement为ON条件
+	Alias		string	//子查询的别名
+	sql			string
+	table		string
+	conditions	[]joinCondition
+	args		[]interface{}
+	built		bool
+	hints		[]string
+	err			error
 }
 
 //Data 数据元素
@@ -60,29 +84,57 @@ type Data struct {
 	Value	interface{}
 }
 
+//Raw 原样写入SQL的表达式，不作为参数绑定
+///如 Data{Field: "num", Value: Raw("num + 1")}，AddAll中同一字段的Raw在每一行中必须相同
+type Raw string
+
 type option struct{
 	table		[]Table
 	distinct	bool
 	field		string
+	rawFields	[]string	//FieldRaw、FieldWindow追加的字段，不会被Field覆盖，生成语句时合并
+	subFields	[]Table
+	fieldArgs	[]interface{}
+	tableArgs	[]interface{}
+	joinArgs	[]interface{}
+	ctes		[]cte
+	cteNames	[]string
+	cteSQL		string
+	cteArgs		[]interface{}
 	join		[]Join
 	where		string
 	whereArgs	[]interface{}
 	group		[]string
 	having		string
+	windows		[]namedWindow
 	order		[]Order
 	limit		Limit
-	union		Union
+	unions		[]unionPart
+	unionArgs	[]interface{}
 	comment		string
+	nested		bool
 	page		string
-	force		string
+	indexHints	[]string
+	hints		[]string
 	fetchSQL	bool
+	strictAlias	bool		//别名未配置时返回错误，不回退到"default"
+	trashed		int
+	lock		string
+	tx			*Tx
+	with		[]string
+	withoutScopes	[]string
+	ctx			context.Context
+	withoutTenant	bool
+	noTimestamps	bool
+	err			[]error
 }
 
 var exp = map[string]string {
-	"EQ"		: "==",
+	"EQ"		: "=",
 	"NEQ" 		: "<>",
 	"GT"		: ">",
-	"NGT"		: ">=",
+	"EGT"		: ">=",
+	"NGT"		: ">=",		//兼容旧的写法，同EGT
 	"LT"		: "<",
 	"ELT"		: "<=",
 	"NLIKE"		: "NOT LIKE",
@@ -98,176 +150,338 @@ type ModelHook interface {
 	
 }
 
-var selectSQL = "SELECT%DISTINCT% %FIELD% FROM %TABLE%%JOIN%%WHERE%%GROUP%%HAVING%%ORDER%%LIMIT% %UNION%%COMMENT%"
+var selectSQL = "%CTE%SELECT%HINT%%DISTINCT% %FIELD% FROM %TABLE%%JOIN%%WHERE%%GROUP%%HAVING%%WINDOW%%ORDER%%LIMIT%%LOCK% %COMMENT%"
 var insertSQL = "INSERT INTO %TABLE%(%FIELD%) VALUE(%MARK%)"
 var updateSQL = "UPDATE %TABLE% SET %FIELD% WHERE %ARGS%"
-var deleteSQL = "DELETE FORM %TABLE% WHERE %AGRS%"
+var deleteSQL = "DELETE FROM %TABLE% WHERE %ARGS%"
 
 //Table 指定当前的数据表
 func (m *Model) Table(tables ...Table) *Model {
-	m.initOption()
-	m.options.table = append(m.options.table, tables...)
-	return m
+	q := m.clone()
+	for _, table := range tables {
+		if table.Sub != nil {
+			if !identifierPart.MatchString(table.Alias) {
+				q.options.err = append(q.options.err, fmt.Errorf("[Model Table]: The alias of subquery is required : %w", ErrInvalidIdentifier))
+				continue
+			}
+			q.options.table = append(q.options.table, table)
+			continue
+		}
+		if _, err := quoteIdentifier(table.Name); err != nil {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Table]: %w", err))
+			continue
+		}
+		if table.Alias != "" && !identifierPart.MatchString(table.Alias) {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Table]: %s : %w", table.Alias, ErrInvalidIdentifier))
+			continue
+		}
+		q.options.table = append(q.options.table, table)
+	}
+	return q
 }
 
-//Field 指定字段名
+//Field 指定字段名，支持 column、table.column、column AS alias，表达式使用FieldRaw
 func (m *Model) Field(fields ...string) *Model {
-	m.initOption()
-	if len(fields) == 0 {
-		m.options.field = ""
-	} else {
-		m.options.field = strings.Join(fields, ",")
+	q := m.clone()
+	var quoted []string
+	for _, field := range fields {
+		f, err := quoteField(field)
+		if err != nil {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Field]: %w", err))
+			continue
+		}
+		quoted = append(quoted, f)
 	}
-	return m
+	q.options.field = strings.Join(quoted, ",")
+	return q
 }
 
-//Where 指定查询条件
-func (m *Model) Where(where string, args ...interface{}) (model *Model) {
-	m.initOption()
+//Where 指定查询条件，多次调用时每个条件加括号后AND
+func (m *Model) Where(where string, args ...interface{}) *Model {
+	q := m.clone()
 	if args != nil && where == "" {
-		m.err = append(m.err, "[Model Where] : The Condition is nil")
-		return 
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Where] : %w", ErrEmptyCondition))
+		return q
 	}
-	if m.options.where == "" {
-		m.options.where = where
+	if q.options.where == "" {
+		q.options.where = where
 	} else {
-		m.options.where = fmt.Sprintf(" %s and %s ", m.options.where, where)
+		//每个条件加括号，条件中的OR不会影响其他条件(如Scope添加的条件)
+		q.options.where = fmt.Sprintf(" (%s) and (%s) ", strings.TrimSpace(q.options.where), where)
 	}
-	m.options.whereArgs = append(m.options.whereArgs, args...)
-	return m
+	q.options.whereArgs = append(q.options.whereArgs, args...)
+	return q
 }
 
-//Order 对操作的结果排序
+//Order 对操作的结果排序，设置了Sortable时只能使用其中的字段，表达式使用OrderRaw
 func (m *Model) Order(orders ...Order) *Model {
-	m.initOption()
-	m.options.order = append(m.options.order, orders...)
-	return m
+	q := m.clone()
+	for _, order := range orders {
+		field, err := quoteIdentifier(order.Field)
+		if err != nil {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Order]: %w", err))
+			continue
+		}
+		if !allowColumn(m.Sortable, order.Field) {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Order]: %s : %w", order.Field, ErrColumnNotAllowed))
+			continue
+		}
+		q.options.order = append(q.options.order, Order{Field: field, Desc: order.Desc})
+	}
+	return q
 }
 
 //Limit 指定查询和操作的数量
 func (m *Model) Limit(limit Limit) *Model {
-	m.initOption()
-	m.options.limit = limit
-	return m
+	q := m.clone()
+	q.options.limit = limit
+	return q
 }
 
 //Page 指定分页
 ///page 页数
 ///listRows 每页数量
 func (m *Model) Page(page int, listRows int) *Model {
-	m.initOption()
-	m.options.page = fmt.Sprintf(" %d,%d ", page, listRows)
-	return m
+	q := m.clone()
+	q.options.page = fmt.Sprintf(" %d,%d ", page, listRows)
+	return q
 }
 
-//Group 一个或多个列对结果集进行分组
+//Group 一个或多个列对结果集进行分组，表达式使用GroupRaw
 func (m *Model) Group(fields ...string) *Model {
-	m.initOption()
-	m.options.group = append(m.options.group, fields...)
-	return m
+	q := m.clone()
+	for _, field := range fields {
+		group, err := quoteIdentifier(field)
+		if err != nil {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Group]: %w", err))
+			continue
+		}
+		q.options.group = append(q.options.group, group)
+	}
+	return q
 }
 
 //Having 配合group方法完成从分组的结果中筛选
 func (m *Model) Having(having string) *Model {
-	m.initOption()
-	m.options.having = having
-	return m
+	q := m.clone()
+	q.options.having = having
+	return q
 }
 
 //Distinct 用于返回唯一不同的值
 func (m *Model) Distinct(distinct bool) *Model {
-	m.initOption()
-	m.options.distinct = distinct
-	return m
+	q := m.clone()
+	q.options.distinct = distinct
+	return q
 }
 
 //Join 用于根据两个或多个表中的列之间的关系，从这些表中查询数据
 func (m *Model) Join(join Join) *Model {
-	m.initOption()
-	m.options.join = append(m.options.join, join)
-	return m
+	q := m.clone()
+	if join.err != nil {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Join]: %w", join.err))
+		return q
+	}
+	if join.Sub != nil && !identifierPart.MatchString(join.Alias) {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Join]: The alias of subquery is required : %w", ErrInvalidIdentifier))
+		return q
+	}
+	q.options.join = append(q.options.join, join)
+	return q
 }
 
-//Union 用于合并两个或多个SELECT语句的结果集
+//Union 用于合并两个或多个SELECT语句的结果集，合并其他查询使用UnionQuery、UnionAll
 func (m *Model) Union(selectSQL []string, all bool) *Model {
-	m.initOption()
-	if all {
-		m.options.union.All = all
+	q := m.clone()
+	for _, sql := range selectSQL {
+		q.options.unions = append(q.options.unions, unionPart{sql: sql, all: all})
 	}
-	m.options.union.SelectSQL = append(m.options.union.SelectSQL, selectSQL...)
-	return m
+	return q
+}
+
+//WithContext 指定执行语句使用的上下文
+func (m *Model) WithContext(ctx context.Context) *Model {
+	q := m.clone()
+	q.options.ctx = ctx
+	return q
 }
 
 //LastSQL 最后执行生成的SQL语句
 func (m *Model) LastSQL() string {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 	return m.sql
 }
 
-//Error	执行过程中出现的所有错误
+//Error	最后一次执行过程中出现的所有错误
 func (m *Model) Error() error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
 	if len(m.err) > 0 {
-		str := fmt.Sprintf("\n[Model Error]:\n %s \n", strings.Join(m.err, "\n"))
-		return errors.New(str)
+		return &ModelError{Errs: append([]error(nil), m.err...)}
 	}
 	return nil
 }
 
 //Find 查找数据
 func (m *Model) Find(dest interface{}) error {
-	defer func(){
-		m.options = nil
-	}()
-	m.Limit(Limit{
+	if m.Sharding != nil {
+		return m.shardFind(dest)
+	}
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return m.record("", errs...)
+	}
+	options.limit = Limit{
 		Offset : 1,
-	})
-	m.sql = m.parseSelectSQL(selectSQL, m.options)
-	if m.sql == "" {
-		m.err = append(m.err, "[Model Find]:The SQL is null of string")
-		return m.Error()
 	}
-	db, err := getDB(m.getDBAlias())
+	m.scopeSoftDelete(options)
+	if err := m.scopeTenant(options); err != nil {
+		return m.record("", err)
+	}
+	sql := m.parseSelectSQL(selectSQL, options)
+	if sql == "" {
+		return m.record(sql, errors.New("[Model Find]:The SQL is null of string"))
+	}
+	args := options.args()
+	db, err := m.getExecutor(options)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return m.Error()
+		return m.recordArgs(sql, args, classifyError(err))
 	}
-	if err = db.Get(dest, m.sql, m.options.whereArgs...); err != nil {
-		m.err = append(m.err, err.Error())
-		return m.Error()
+	if err = db.GetContext(options.context(), dest, sql, args...); err != nil {
+		return m.recordArgs(sql, args, classifyError(err))
 	}
-	return nil
+	if err = m.eagerLoad(options, dest); err != nil {
+		return m.recordArgs(sql, args, err)
+	}
+	return m.recordArgs(sql, args)
 }
 
 //Select 查询数据
 func (m *Model) Select(dest interface{}) error {
-	defer func(){
-		m.options = nil
-	}()
-	m.sql = m.parseSelectSQL(selectSQL, m.options)
-	if m.sql == "" {
-		m.err = append(m.err, "[Model Find]:The SQL is null of string")
-		return m.Error()
-	}
-	db, err := getDB(m.getDBAlias())
+	if m.Sharding != nil {
+		return m.shardSelect(dest)
+	}
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return m.record("", errs...)
+	}
+	m.scopeSoftDelete(options)
+	if err := m.scopeTenant(options); err != nil {
+		return m.record("", err)
+	}
+	sql := m.parseSelectSQL(selectSQL, options)
+	if sql == "" {
+		return m.record(sql, errors.New("[Model Find]:The SQL is null of string"))
+	}
+	args := options.args()
+	db, err := m.getExecutor(options)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return m.Error()
+		return m.recordArgs(sql, args, classifyError(err))
 	}
-	if err = db.Select(dest, m.sql, m.options.whereArgs...); err != nil {
-		m.err = append(m.err, err.Error())
-		return m.Error()
+	if err = db.SelectContext(options.context(), dest, sql, args...); err != nil {
+		return m.recordArgs(sql, args, classifyError(err))
 	}
-	return nil
+	if err = m.eagerLoad(options, dest); err != nil {
+		return m.recordArgs(sql, args, err)
+	}
+	return m.recordArgs(sql, args)
 }
 
+//Count 统计数量，不传入字段时统计全部记录
+func (m *Model) Count(field ...string) (int64, error) {
+	var count int64
+	f := "*"
+	if len(field) > 0 {
+		f = field[0]
+	}
+	if err := m.aggregate("COUNT", f, &count); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+//Sum 求和
+func (m *Model) Sum(field string) (float64, error) {
+	var sum sql.NullFloat64
+	if err := m.aggregate("SUM", field, &sum); err != nil {
+		return 0, err
+	}
+	return sum.Float64, nil
+}
+
+//Max 求最大值
+func (m *Model) Max(field string) (float64, error) {
+	var max sql.NullFloat64
+	if err := m.aggregate("MAX", field, &max); err != nil {
+		return 0, err
+	}
+	return max.Float64, nil
+}
+
+//Min 求最小值
+func (m *Model) Min(field string) (float64, error) {
+	var min sql.NullFloat64
+	if err := m.aggregate("MIN", field, &min); err != nil {
+		return 0, err
+	}
+	return min.Float64, nil
+}
+
+func (m *Model) aggregate(function string, field string, dest interface{}) error {
+	if m.Sharding != nil {
+		return m.shardAggregate(function, field, dest)
+	}
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return m.record("", errs...)
+	}
+	field, err := quoteAggregate(field)
+	if err != nil {
+		return m.record("", fmt.Errorf("[Model %s]: %w", function, err))
+	}
+	options.order = nil
+	options.limit = Limit{}
+	m.scopeSoftDelete(options)
+	if err := m.scopeTenant(options); err != nil {
+		return m.record("", err)
+	}
+	var sql string
+	if len(options.unions) > 0 {
+		//合并的结果集作为派生表聚合
+		sql = fmt.Sprintf("SELECT %s(%s) FROM (%s) AS `union_result`", function, field, m.parseSelectSQL(selectSQL, options))
+	} else {
+		options.field = fmt.Sprintf("%s(%s)", function, field)
+		options.fieldArgs = nil
+		sql = m.parseSelectSQL(selectSQL, options)
+	}
+	args := options.args()
+	db, err := m.getExecutor(options)
+	if  err != nil {
+		return m.recordArgs(sql, args, classifyError(err))
+	}
+	if err = db.GetContext(options.context(), dest, sql, args...); err != nil {
+		return m.recordArgs(sql, args, classifyError(err))
+	}
+	return m.recordArgs(sql, args)
+}
 
 //Add 新增数据
 func (m *Model) Add(datas ...Data) (int64, error) {
-	defer func(){
-		m.options = nil
-	}()
+	if m.Sharding != nil {
+		return m.shardAdd(datas)
+	}
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return -1, m.record("", errs...)
+	}
 	if len(datas) == 0 {
-		m.err = append(m.err, "[Model Add]:The datas is null")
-		return -1, m.Error()
+		return -1, m.record("", errors.New("[Model Add]:The datas is null"))
+	}
+	datas = m.fillTimestamps(options, datas, true)
+	datas, err := m.fillTenant(options, datas)
+	if err != nil {
+		return -1, m.record("", err)
 	}
 	var fields []string
 	var values []interface{}
@@ -275,125 +489,194 @@ func (m *Model) Add(datas ...Data) (int64, error) {
 		fields = append(fields, data.Field)
 		values = append(values, data.Value)
 	}
-	m.sql = m.parseInsertSQL(insertSQL, fields...)
+	fields, err = quoteFields(fields)
+	if err != nil {
+		return -1, m.record("", fmt.Errorf("[Model Add]: %w", err))
+	}
+	marks, rows, err := m.insertMarks([][]interface{}{values})
+	if err != nil {
+		return -1, m.record("", fmt.Errorf("[Model Add]: %w", err))
+	}
+	values = rows[0]
+	sql := m.parseInsertSQL(insertSQL, options, fields, marks) + m.parseComment(options)
 	
-	db, err := getDB(m.getDBAlias())
+	db, err := m.getExecutor(options)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, values, classifyError(err))
 	}
-	result ,err := db.Exec(m.sql, values...)
+	result ,err := db.ExecContext(options.context(), sql, values...)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, values, classifyError(err))
 	}
 	id, err := result.LastInsertId()
 	if err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, values, classifyError(err))
 	}
-	return id, nil
+	return id, m.recordArgs(sql, values)
 }
 
 //AddAll 新增多条数据
 func (m *Model) AddAll(datas ...[]Data) error {
-	defer func(){
-		m.options = nil
-	}()
+	if m.Sharding != nil {
+		return m.shardAddAll(datas)
+	}
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return m.record("", errs...)
+	}
 	if len(datas) == 0 {
-		m.err = append(m.err, "[Model AddAll]:The datas is null")
-		return m.Error()
+		return m.record("", errors.New("[Model AddAll]:The datas is null"))
 	}
+	rows := make([][]Data, 0, len(datas))
+	for _, data := range datas {
+		row, err := m.fillTenant(options, m.fillTimestamps(options, data, true))
+		if err != nil {
+			return m.record("", err)
+		}
+		rows = append(rows, row)
+	}
+	datas = rows
 	fields, err := m.verifyFiled(datas...)
 	if err != nil {
-		m.err = append(m.err, err.Error())
-		return m.Error()
+		return m.record("", err)
 	}
-	field, values := m.extractValue(fields, datas...)
-	db, err := getDB(m.getDBAlias())
-	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return m.Error()
-	}
-	m.sql = m.parseInsertSQL(insertSQL, field)
-	tx := db.MustBegin()
-	for _, value := range values {
-		result := tx.MustExec(m.sql, value...)
-		_, err := result.LastInsertId()
-		if err != nil {
-			m.err = append(m.err, err.Error())
-			tx.Rollback()
-			return m.Error()
+	_, values := m.extractValue(fields, datas...)
+	fields, err = quoteFields(fields)
+	if err != nil {
+		return m.record("", fmt.Errorf("[Model AddAll]: %w", err))
+	}
+	marks, values, err := m.insertMarks(values)
+	if err != nil {
+		return m.record("", fmt.Errorf("[Model AddAll]: %w", err))
+	}
+	sql := m.parseInsertSQL(insertSQL, options, fields, marks) + m.parseComment(options)
+	//记录最后执行的一行的参数
+	var last []interface{}
+	err = m.transaction(options, func(exec executor) error {
+		for _, value := range values {
+			last = value
+			if _, err := exec.ExecContext(options.context(), sql, value...); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return m.recordArgs(sql, last, classifyError(err))
 	}
-	tx.Commit()
-	return nil
+	return m.recordArgs(sql, last)
 }
 
 //Update 更新数据
+///开启软删除时只更新未删除的数据，更新已删除的数据使用 WithTrashed 或 OnlyTrashed
 func (m *Model) Update(datas ...Data) (int64, error) {
-	defer func(){
-		m.options = nil
-	}()
+	if m.Sharding != nil {
+		for _, data := range datas {
+			if data.Field == m.Sharding.Key {
+				return -1, m.record("", fmt.Errorf("[Model Update]: The shard key `%s` can't be updated, the row would stay on the old shard", data.Field))
+			}
+		}
+		//乐观锁按全部分片影响的总行数判断是否过期，单个分片未匹配不是冲突
+		return m.shardExec("[Model Update]", func(target *Model) (int64, error) {
+			return target.update(datas, false)
+		}, m.verifyVersion)
+	}
+	return m.update(datas, true)
+}
+
+//verifyVersion 开启乐观锁时，没有更新任何行说明版本号已过期
+func (m *Model) verifyVersion(total int64) error {
+	if m.Version != "" && total == 0 && !m.options.dryRun() {
+		return fmt.Errorf("[Model Update]: %w", ErrStaleObject)
+	}
+	return nil
+}
+
+func (m *Model) update(datas []Data, verify bool) (int64, error) {
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return -1, m.record("", errs...)
+	}
 	if len(datas) == 0 {
-		m.err = append(m.err, "[Model Update]: The datas is null")
-		return -1, m.Error()
+		return -1, m.record("", errors.New("[Model Update]: The datas is null"))
+	}
+	if options.where == "" && len(options.whereArgs) == 0 {
+		return -1, m.record("", fmt.Errorf("[Model Update]: %w", ErrEmptyCondition))
 	}
-	if m.options.where == "" && len(m.options.whereArgs) == 0 {
-		m.err = append(m.err, "[Model Update]: The Condition is null")
-		return -1, m.Error()
+	if err := m.checkTenant(options, datas); err != nil {
+		return -1, m.record("", err)
 	}
-	var args []interface{}
-	var err error
-	m.sql, args, err = m.parseUpdateSQL(updateSQL, m.options.where, m.options.whereArgs, datas...)
+	m.scopeSoftDelete(options)
+	if err := m.scopeTenant(options); err != nil {
+		return -1, m.record("", err)
+	}
+	datas = m.fillTimestamps(options, datas, false)
+	datas, err := m.applyVersion(options, datas)
+	if err != nil {
+		return -1, m.record("", err)
+	}
+	sql, args, err := m.parseUpdateSQL(updateSQL, options.where, options.whereArgs, datas...)
 	if err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.record(sql, err)
 	}
-	db, err := getDB(m.getDBAlias())
+	sql += m.parseComment(options)
+	db, err := m.getExecutor(options)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, args, classifyError(err))
 	}
-	result ,err := db.Exec(m.sql, args...)
+	result ,err := db.ExecContext(options.context(), sql, args...)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, args, classifyError(err))
 	}
 	id, err := result.RowsAffected()
 	if err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, args, classifyError(err))
 	}
-	return id, nil
+	if verify {
+		if err = m.verifyVersion(id); err != nil {
+			return 0, m.recordArgs(sql, args, err)
+		}
+	}
+	return id, m.recordArgs(sql, args)
 }
 
 //Delete 删除数据
+///开启软删除时，仅标记为已删除
 func (m *Model) Delete() (int64, error) {
-	defer func(){
-		m.options = nil
-	}()
-	if m.options.where == "" && len(m.options.whereArgs) == 0 {
-		m.err = append(m.err, "[Model Delete]: The Condition is null")
-		return -1, m.Error()
-	}
-	m.sql = m.parseDeleteSQL(deleteSQL, m.options.where)
-	db, err := getDB(m.getDBAlias())
+	if m.Sharding != nil {
+		return m.shardExec("[Model Delete]", (*Model).Delete, nil)
+	}
+	if m.SoftDelete != nil {
+		return m.softDelete()
+	}
+	return m.destroy()
+}
+
+func (m *Model) destroy() (int64, error) {
+	options, errs := m.prepare()
+	if len(errs) > 0 {
+		return -1, m.record("", errs...)
+	}
+	if options.where == "" && len(options.whereArgs) == 0 {
+		return -1, m.record("", fmt.Errorf("[Model Delete]: %w", ErrEmptyCondition))
+	}
+	if err := m.scopeTenant(options); err != nil {
+		return -1, m.record("", err)
+	}
+	sql := m.parseDeleteSQL(deleteSQL, options.where) + m.parseComment(options)
+	db, err := m.getExecutor(options)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, options.whereArgs, classifyError(err))
 	}
-	result ,err := db.Exec(m.sql, m.options.whereArgs...)
+	result ,err := db.ExecContext(options.context(), sql, options.whereArgs...)
 	if  err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, options.whereArgs, classifyError(err))
 	}
 	id, err := result.RowsAffected()
 	if err != nil {
-		m.err = append(m.err, err.Error())
-		return -1, m.Error()
+		return -1, m.recordArgs(sql, options.whereArgs, classifyError(err))
 	}
-	return id, nil
+	return id, m.recordArgs(sql, options.whereArgs)
 }
 
 func (m *Model) getDBAlias() string {
@@ -404,44 +687,254 @@ func (m *Model) getDBAlias() string {
 }
 
 func (m *Model) getTableName() string {
-	if m.TableName == "" {
-		m.err = append(m.err, "[Model getTableName]: The TableName is nil")
+	return m.tableName(m.TableName)
+}
+
+//getPrefix 获取数据表前缀，Model未设置时使用本次操作路由到的数据库配置中的Prefix
+///租户数据库在prepare时已经连接，这里只获取别名不连接
+func (m *Model) getPrefix() string {
+	if m.Prefix != "" {
+		return m.Prefix
+	}
+	alias, _, _ := m.routeAlias(m.options)
+	return getPrefix(alias)
+}
+
+var plainTableName = regexp.MustCompile(`^\w+$`)
+
+//tableName 为表名加上前缀，只有单独的表名会自动加上前缀，其他形式使用 __PREFIX__ 占位
+///如 Table{Name: "user"} 为 b_user，Table{Name: "other_db.__PREFIX__user"} 为 other_db.b_user
+func (m *Model) tableName(name string) string {
+	if !plainTableName.MatchString(name) {
+		return name
+	}
+	return m.getPrefix() + name
+}
+
+//parsePrefix 替换语句中的 __PREFIX__ 为数据表前缀，用于Join、Where、Union等语句
+func (m *Model) parsePrefix(sql string) string {
+	if !strings.Contains(sql, "__PREFIX__") {
+		return sql
+	}
+	return strings.Replace(sql, "__PREFIX__", m.getPrefix(), -1)
+}
+
+//qualify 联表查询时为字段加上主表的表名或别名
+func (m *Model) qualify(options *option, field string) string {
+	if len(options.join) == 0 {
+		return field
+	}
+	table := m.getTableName()
+	if len(options.table) > 0 {
+		table = m.parsePrefix(m.tableName(options.table[0].Name))
+		if options.table[0].Alias != "" {
+			table = options.table[0].Alias
+		}
+	}
+	return fmt.Sprintf("%s.%s", table, field)
+}
+
+//clone 复制出新的查询，基础Model不会被修改，可以在多个goroutine中共用
+func (m *Model) clone() *Model {
+	return &Model{
+		DBAlias		: m.DBAlias,
+		TableName	: m.TableName,
+		Prefix		: m.Prefix,
+		SoftDelete	: m.SoftDelete,
+		Timestamps	: m.Timestamps,
+		Version		: m.Version,
+		Relations	: m.Relations,
+		Scopes		: m.Scopes,
+		GlobalScopes	: m.GlobalScopes,
+		TenantColumn	: m.TenantColumn,
+		Sharding	: m.Sharding,
+		Sortable	: m.Sortable,
+		Filterable	: m.Filterable,
+		options		: m.options.clone(),
+	}
+}
+
+func (o *option) clone() *option {
+	if o == nil {
+		return &option{}
+	}
+	c := *o
+	c.table = append([]Table(nil), o.table...)
+	c.join = append([]Join(nil), o.join...)
+	c.rawFields = append([]string(nil), o.rawFields...)
+	c.subFields = append([]Table(nil), o.subFields...)
+	c.fieldArgs = append([]interface{}(nil), o.fieldArgs...)
+	c.tableArgs = append([]interface{}(nil), o.tableArgs...)
+	c.joinArgs = append([]interface{}(nil), o.joinArgs...)
+	c.ctes = append([]cte(nil), o.ctes...)
+	c.cteNames = append([]string(nil), o.cteNames...)
+	c.cteArgs = append([]interface{}(nil), o.cteArgs...)
+	c.whereArgs = append([]interface{}(nil), o.whereArgs...)
+	c.group = append([]string(nil), o.group...)
+	c.order = append([]Order(nil), o.order...)
+	c.windows = append([]namedWindow(nil), o.windows...)
+	c.indexHints = append([]string(nil), o.indexHints...)
+	c.hints = append([]string(nil), o.hints...)
+	c.unions = append([]unionPart(nil), o.unions...)
+	c.unionArgs = append([]interface{}(nil), o.unionArgs...)
+	c.with = append([]string(nil), o.with...)
+	c.withoutScopes = append([]string(nil), o.withoutScopes...)
+	c.err = append([]error(nil), o.err...)
+	return &c
+}
+
+func (o *option) context() context.Context {
+	if o.ctx == nil {
+		return context.Background()
+	}
+	return o.ctx
+}
+
+//mergeFields 将FieldRaw、FieldWindow追加的字段合并到Field指定的字段之后
+func (o *option) mergeFields() {
+	if len(o.rawFields) == 0 {
+		return
+	}
+	fields := strings.Join(o.rawFields, ",")
+	if o.field == "" {
+		o.field = fields
+	} else {
+		o.field += "," + fields
+	}
+	o.rawFields = nil
+}
+
+//and 追加查询条件，已有的条件作为一个整体
+func (o *option) and(where string, args ...interface{}) {
+	if o.where == "" {
+		o.where = where
+	} else {
+		o.where = fmt.Sprintf(" (%s) and %s ", o.where, where)
+	}
+	o.whereArgs = append(o.whereArgs, args...)
+}
+
+//bind 使关联的查询使用相同的事务、上下文和预览模式
+func (o *option) bind(m *Model) *Model {
+	q := m.clone()
+	q.options.tx = o.tx
+	q.options.ctx = o.ctx
+	q.options.fetchSQL = q.options.fetchSQL || o.fetchSQL
+	return q
+}
+
+//prepare 获取本次执行使用的查询条件及构建过程中产生的错误
+func (m *Model) prepare() (*option, []error) {
+	options := m.applyGlobalScopes().options.clone()
+	options.mergeFields()
+	errs := options.err
+	if m.getTableName() == "" && len(options.table) == 0 {
+		errs = append(errs, errors.New("[Model getTableName]: The TableName is nil"))
+	}
+	if options.lock != "" && options.tx == nil {
+		errs = append(errs, fmt.Errorf("[Model Lock]: %w", ErrNotInTransaction))
+	}
+	if len(options.indexHints) > 0 && len(options.table) > 0 && options.table[0].Sub != nil {
+		errs = append(errs, errors.New("[Model IndexHint]: The index hint can't be used on a subquery table"))
+	}
+	//先连接路由到的租户数据库，生成语句时才能使用租户配置的数据表前缀
+	if len(errs) == 0 && options.tx == nil && !options.dryRun() {
+		if _, err := m.resolveAlias(options); err != nil {
+			errs = append(errs, err)
+		}
 	}
-	return m.TableName
+	if len(errs) == 0 {
+		m.fullJoin(options)
+		//合并的结果集不能加锁，FullJoin也通过UNION生成
+		if options.lock != "" && len(options.unions) > 0 {
+			errs = append(errs, errors.New("[Model Lock]: The lock can't be used with union"))
+		}
+		if err := options.compileSubqueries(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return options, errs
 }
 
-func (m *Model) initOption() {
-	m.initLock.Lock()
-	defer m.initLock.Unlock()
-	if m.options == nil {
-		m.options = &option{}
+//record 记录本次执行的SQL及错误，每次执行都会覆盖上一次的结果
+func (m *Model) record(sql string, errs ...error) error {
+	return m.recordArgs(sql, nil, errs...)
+}
+
+//recordArgs 记录本次执行的SQL、参数及错误，SQL和参数在同一次调用中写入
+func (m *Model) recordArgs(sql string, args []interface{}, errs ...error) error {
+	m.stateMu.Lock()
+	defer m.stateMu.Unlock()
+	m.sql = sql
+	m.args = append([]interface{}(nil), args...)
+	m.err = nil
+	for _, err := range errs {
+		if err != nil {
+			m.err = append(m.err, err)
+		}
+	}
+	if len(m.err) > 0 {
+		return &ModelError{Errs: append([]error(nil), m.err...)}
 	}
+	return nil
 }
 
 func (m *Model) parseSelectSQL(sql string, options *option) string {
-	sql = strings.Replace(sql, "%TABLE%", m.parseTable(m.options.table...), -1)
+	if len(options.unions) > 0 {
+		return m.parseUnionSQL(sql, options)
+	}
+	sql = strings.Replace(sql, "%CTE%", options.cteSQL, -1)
+	sql = strings.Replace(sql, "%HINT%", m.parseHint(options.hints...), -1)
+	sql = strings.Replace(sql, "%TABLE%", m.parseFrom(options), -1)
 	sql = strings.Replace(sql, "%DISTINCT%", m.parseDistinct(options.distinct), -1)
 	sql = strings.Replace(sql, "%FIELD%", m.parseField(options.field), -1)
 	sql = strings.Replace(sql, "%JOIN%", m.parseJoin(options.join...), -1)
-	sql = strings.Replace(sql, "%WHERE%", m.parseWhere(m.options.where), -1)
-	sql = strings.Replace(sql, "%GROUP%", m.parseGroup(m.options.group...), -1)
-	sql = strings.Replace(sql, "%HAVING%", m.parseHaving(m.options.having), -1)
-	sql = strings.Replace(sql, "%ORDER%", m.parseOrder(m.options.order...), -1)
-	sql = strings.Replace(sql, "%LIMIT%", m.parseLimit(m.options.limit), -1)
-	sql = strings.Replace(sql, "%UNION%", m.parseUnion(m.options.union), -1)
-	sql = strings.Replace(sql, "%COMMENT%", m.parseComment(m.options.comment), -1)
-	return sql
-}
-
-func (m *Model) parseInsertSQL(sql string, fields ...string) string {
-	sql = strings.Replace(sql, "%TABLE%", m.parseTable(m.options.table...), -1)
+	sql = strings.Replace(sql, "%WHERE%", m.parseWhere(options.where), -1)
+	sql = strings.Replace(sql, "%GROUP%", m.parseGroup(options.group...), -1)
+	sql = strings.Replace(sql, "%HAVING%", m.parseHaving(options.having), -1)
+	sql = strings.Replace(sql, "%WINDOW%", m.parseWindow(options.windows...), -1)
+	sql = strings.Replace(sql, "%ORDER%", m.parseOrder(options.order...), -1)
+	sql = strings.Replace(sql, "%LIMIT%", m.parseLimit(options.limit), -1)
+	sql = strings.Replace(sql, "%LOCK%", options.lock, -1)
+	sql = strings.Replace(sql, "%COMMENT%", m.parseComment(options), -1)
+	return m.parsePrefix(sql)
+}
+
+func (m *Model) parseInsertSQL(sql string, options *option, fields []string, marks []string) string {
+	sql = strings.Replace(sql, "%TABLE%", m.parseTable(options.table...), -1)
 	sql = strings.Replace(sql, "%FIELD%", strings.Join(fields, ","), -1)
-	var fieldMark []string
-	for i := 0; i < len(fields); i++ {
-		fieldMark = append(fieldMark, "?")
+	sql = strings.Replace(sql, "%MARK%", strings.Join(marks, ","), -1)
+	return m.parsePrefix(sql)
+}
+
+//insertMarks 生成VALUE中的占位符，Raw原样写入并从参数中移除，多行时同一字段的Raw必须相同
+func (m *Model) insertMarks(rows [][]interface{}) ([]string, [][]interface{}, error) {
+	if len(rows) == 0 {
+		return nil, rows, nil
 	}
-	sql = strings.Replace(sql, "%MARK%", strings.Join(fieldMark, ","), -1)
-	return sql
+	marks := make([]string, len(rows[0]))
+	raws := make([]bool, len(rows[0]))
+	for i, value := range rows[0] {
+		marks[i] = "?"
+		if raw, ok := value.(Raw); ok {
+			marks[i], raws[i] = string(raw), true
+		}
+	}
+	result := make([][]interface{}, 0, len(rows))
+	for _, row := range rows {
+		var args []interface{}
+		for i, value := range row {
+			raw, ok := value.(Raw)
+			if ok != raws[i] || (ok && string(raw) != marks[i]) {
+				return nil, nil, errors.New("The Raw value must be the same in every row")
+			}
+			if !ok {
+				args = append(args, value)
+			}
+		}
+		result = append(result, args)
+	}
+	return marks, result, nil
 }
 
 func (m *Model) parseUpdateSQL(sql, where string, whereArgs []interface{}, datas ...Data) (string, []interface{}, error)  {
@@ -452,7 +945,15 @@ func (m *Model) parseUpdateSQL(sql, where string, whereArgs []interface{}, datas
 	for _, data := range datas {
 		if !vaild[data.Field] {
 			vaild[data.Field] = true
-			fields = append(fields, fmt.Sprintf(" %s = ? ", data.Field))
+			field, err := quoteIdentifier(data.Field)
+			if err != nil {
+				return "", nil, fmt.Errorf("[Model parseUpdateSQL]: %w", err)
+			}
+			if raw, ok := data.Value.(Raw); ok {
+				fields = append(fields, fmt.Sprintf(" %s = %s ", field, raw))
+				continue
+			}
+			fields = append(fields, fmt.Sprintf(" %s = ? ", field))
 			values = append(values, data.Value)
 		} else {
 			return "", nil, fmt.Errorf("[Model parseUpdateSQL]: Field '%s' is repeat", data.Field)
@@ -461,15 +962,15 @@ func (m *Model) parseUpdateSQL(sql, where string, whereArgs []interface{}, datas
 	sql = strings.Replace(sql, "%FIELD%", strings.Join(fields, ","), -1)
 	sql = strings.Replace(sql, "%ARGS%", where, -1)
 	if len(whereArgs) > 0 {
-		values = append(values, whereArgs)
+		values = append(values, whereArgs...)
 	}
-	return sql, values, nil
+	return m.parsePrefix(sql), values, nil
 }
 
 func (m *Model) parseDeleteSQL(sql string, where string) string {
 	sql = strings.Replace(sql, "%TABLE%", m.getTableName(), -1)
-	sql = strings.Replace(sql, "%ARGS", where, -1)
-	return sql
+	sql = strings.Replace(sql, "%ARGS%", where, -1)
+	return m.parsePrefix(sql)
 }
 
 func (m *Model) verifyFiled(datas ...[]Data) ([]string, error) {
@@ -520,7 +1021,12 @@ func (m *Model) parseTable(tables ...Table) string {
 	if len(tables) > 0 {
 		var table []string
 		for _, Table := range tables {
-			table = append(table, fmt.Sprintf(" %s %s ", Table.Name, Table.Alias))
+			if Table.Sub != nil {
+				table = append(table, fmt.Sprintf(" (%s) %s %s", Table.sql, Table.Alias, Table.hint))
+				continue
+			}
+			name, _ := quoteIdentifier(m.parsePrefix(m.tableName(Table.Name)))
+			table = append(table, fmt.Sprintf(" %s %s %s", name, Table.Alias, Table.hint))
 		}
 		return strings.Join(table, ",")
 	}
@@ -544,29 +1050,38 @@ func (m *Model) parseField(field string) string {
 func (m *Model) parseJoin(joins ...Join) string {
 	var join []string
 	for _, value := range joins {
-		if !(strings.Index(value.Statement, "JOIN") > -1 && strings.Index(value.Statement, "join") > -1) {
-			value.Statement = fmt.Sprintf(" JOIN %s ", value.Statement)
+		keyword, ok := joinKeywords[value.Type]
+		if !ok {
+			keyword = joinKeywords[JoinInner]
+		}
+		if value.built {
+			target := fmt.Sprintf("(%s)", value.sql)
+			if value.Sub == nil {
+				target, _ = quoteIdentifier(m.parsePrefix(m.tableName(value.table)))
+			}
+			statement := fmt.Sprintf("%s %s %s", keyword, target, value.Alias)
+			if len(value.hints) > 0 {
+				statement = fmt.Sprintf("%s %s", statement, strings.Join(value.hints, " "))
+			}
+			if len(value.conditions) > 0 {
+				statement = fmt.Sprintf("%s ON %s", statement, value.parseOn())
+			}
+			join = append(join, statement)
+			continue
 		}
-		switch value.Type {
-		case 0:
-			join = append(join, fmt.Sprintf(" INNER %s ", value.Statement))
-			break
-		case 1:
-			join = append(join, fmt.Sprintf(" LEFT %s ", value.Statement))
-			break
-		case 2:
-			join = append(join, fmt.Sprintf(" RIGHT %s ", value.Statement))
-			break
-		case 3:
-			join = append(join, fmt.Sprintf(" FULL %s ", value.Statement))
-			break
-		default:
-			join = append(join, fmt.Sprintf(" INNER %s ", value.Statement))
-			break
+		if value.Sub != nil {
+			value.Statement = fmt.Sprintf("(%s) %s ON %s", value.sql, value.Alias, value.Statement)
 		}
-
+		if joinedStatement.MatchString(value.Statement) {
+			join = append(join, value.Statement)
+		} else {
+			join = append(join, fmt.Sprintf("%s %s", keyword, value.Statement))
+		}
+	}
+	if len(join) == 0 {
+		return ""
 	}
-	return strings.Join(join, ",")
+	return fmt.Sprintf(" %s ", strings.Join(join, " "))
 }
 
 func (m *Model) parseWhere(where string) string {
@@ -590,19 +1105,14 @@ func (m *Model) parseHaving(having string) string {
 	return ""
 }
 
-func (m *Model) parseComment(comment string) string {
-	if comment != "" {
-		return fmt.Sprintf(" /* %s */", comment)
-	}
-	return ""
-}
-
 func (m *Model) parseOrder(orders ...Order) string {
 	if len(orders) > 0 {
 		var orderStr []string
 		for _, order := range orders {
 			var str string
-			if order.Desc {
+			if order.raw {
+				str = fmt.Sprintf(" %s", order.Field)
+			} else if order.Desc {
 				str = fmt.Sprintf(" %s desc", order.Field)
 			} else {
 				str = fmt.Sprintf(" %s asc", order.Field)
@@ -623,13 +1133,3 @@ func (m *Model) parseLimit(limit Limit) string {
 	}
 	return ""
 }
-
-func (m *Model) parseUnion(union Union) string {
-	if len(union.SelectSQL) > 0 {
-		if union.All {
-			return fmt.Sprintf(" UNION ALL %s ", strings.Join(union.SelectSQL, ","))
-		}
-		return fmt.Sprintf(" UNION %s ", strings.Join(union.SelectSQL, ","))
-	}
-	return ""
-}
\ No newline at end of file
@@ -2,6 +2,8 @@ package mysqlgo
 
 import (
 	"fmt"
+	"strings"
+	"sync"
 	"testing"
 	"time"
 	_ "github.com/go-sql-driver/mysql"
@@ -39,9 +41,10 @@ func TestFind(t *testing.T) {
 				Account 	string
 				Password	string
 			}{}
-			userModel.Field("account", "password").Where("status = ?", 1).Select(&user)
-			fmt.Print(userModel.Error())
-			fmt.Print(userModel.LastSQL())
+			query := userModel.Field("account", "password").Where("status = ?", 1)
+			query.Select(&user)
+			fmt.Print(query.Error())
+			fmt.Print(query.LastSQL())
 			fmt.Print(user)
 		}
 	})
@@ -142,4 +145,65 @@ func TestVerifyField(t *testing.T) {
 		}
 		userModel.AddAll(datas...)
 	})
-}
\ No newline at end of file
+}
+func TestBuilderImmutable(t *testing.T) {
+	t.Run("TestBuilderImmutable", func(t *testing.T) {
+		userModel := &Model {
+			TableName : "b_user",
+		}
+		base := userModel.Where("status = ?", 1)
+		enabled := base.Where("account = ?", "test1")
+		disabled := base.Where("account = ?", "test2")
+		if userModel.options != nil {
+			t.Fatalf("base model is modified : %+v", userModel.options)
+		}
+		if len(base.options.whereArgs) != 1 {
+			t.Fatalf("builder is modified : %v", base.options.whereArgs)
+		}
+		if enabled.options.whereArgs[1] != "test1" || disabled.options.whereArgs[1] != "test2" {
+			t.Fatalf("builder args is shared : %v, %v", enabled.options.whereArgs, disabled.options.whereArgs)
+		}
+
+		var wg sync.WaitGroup
+		for i := 0; i < 10; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				query := userModel.Where("id = ?", i).Order(Order{Field : "id"})
+				query.Select(&[]struct{ ID int }{})
+				if !strings.Contains(query.LastSQL(), "WHERE id = ?") {
+					t.Errorf("query sql fail : %s", query.LastSQL())
+				}
+			}(i)
+		}
+		wg.Wait()
+	})
+}
+
+func TestPrefix(t *testing.T) {
+	userModel := &Model {
+		TableName : "user",
+		Prefix : "b_",
+	}
+	testCases := []struct{
+		query	*Model
+		sql		string
+	}{
+		{query : userModel.Where("id = ?", 1), sql : "FROM b_user WHERE id = ?"},
+		{query : userModel.Table(Table{Name : "order", Alias : "o"}), sql : "FROM  `b_order` o "},
+		{query : userModel.Table(Table{Name : "log.__PREFIX__user"}), sql : "FROM  `log`.`b_user`  "},
+		{query : userModel.Join(Join{Statement : "__PREFIX__order o ON o.user_id = b_user.id"}), sql : "JOIN b_order o ON o.user_id = b_user.id"},
+		{query : userModel.Union([]string{"SELECT * FROM __PREFIX__user_archive"}, true), sql : "UNION ALL SELECT * FROM b_user_archive"},
+	}
+	for _, testCase := range testCases {
+		testCase.query.Select(&[]struct{ ID int }{})
+		if !strings.Contains(testCase.query.LastSQL(), testCase.sql) {
+			t.Fatalf("prefix fail case : %s, sql : %s", testCase.sql, testCase.query.LastSQL())
+		}
+	}
+	query := userModel.Where("id = ?", 1)
+	query.Delete()
+	if !strings.Contains(query.LastSQL(), "DELETE FROM b_user ") {
+		t.Fatalf("prefix delete fail : %s", query.LastSQL())
+	}
+}
@@ -0,0 +1,29 @@
+package mysqlgo
+
+import (
+	"fmt"
+)
+
+//applyVersion 开启乐观锁时，将Data中的版本号作为更新条件，并在更新时递增版本号
+func (m *Model) applyVersion(options *option, datas []Data) ([]Data, error) {
+	if m.Version == "" {
+		return datas, nil
+	}
+	result := make([]Data, 0, len(datas))
+	var version interface{}
+	found := false
+	for _, data := range datas {
+		if data.Field == m.Version {
+			version = data.Value
+			found = true
+			continue
+		}
+		result = append(result, data)
+	}
+	if !found {
+		return nil, fmt.Errorf("[Model Update]: The version field '%s' is required", m.Version)
+	}
+	options.and(fmt.Sprintf("%s = ?", m.Version), version)
+	result = append(result, Data{Field: m.Version, Value: Raw(fmt.Sprintf("%s + 1", m.Version))})
+	return result, nil
+}
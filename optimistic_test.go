@@ -0,0 +1,76 @@
+package mysqlgo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestOptimisticLock(t *testing.T) {
+	t.Run("version condition", func(t *testing.T) {
+		userModel := &Model{
+			TableName: "b_user",
+			Version:   "version",
+		}
+		query := userModel.Where("id = ?", 1)
+		query.Update(Data{Field: "account", Value: "test1"}, Data{Field: "version", Value: 3})
+		want := "UPDATE b_user SET  `account` = ? , `version` = version + 1  WHERE  (id = ?) and version = ?"
+		if !strings.Contains(query.LastSQL(), want) {
+			t.Fatalf("version condition fail : %s", query.LastSQL())
+		}
+
+		query = userModel.Where("id = ?", 1)
+		if _, err := query.Update(Data{Field: "account", Value: "test1"}); err == nil {
+			t.Fatalf("version field should be required")
+		}
+	})
+
+	t.Run("raw value", func(t *testing.T) {
+		userModel := &Model{DBAlias: "raw", TableName: "b_user"}
+		query := userModel.FetchSQL()
+		query.Add(Data{Field: "account", Value: "test1"}, Data{Field: "num", Value: Raw("num + 1")})
+		if query.LastSQL() != "INSERT INTO b_user(`account`,`num`) VALUE(?,num + 1)" || !reflect.DeepEqual(query.LastArgs(), []interface{}{"test1"}) {
+			t.Fatalf("raw insert fail : %s %v", query.LastSQL(), query.LastArgs())
+		}
+		err := query.AddAll(
+			[]Data{{Field: "account", Value: "test1"}, {Field: "created_at", Value: Raw("NOW()")}},
+			[]Data{{Field: "account", Value: "test2"}, {Field: "created_at", Value: Raw("NOW()")}},
+		)
+		//AddAll的字段顺序不固定
+		if err != nil || strings.Count(query.LastSQL(), "?") != 1 || !strings.Contains(query.LastSQL(), "NOW()") {
+			t.Fatalf("raw insert all fail : %s %v", query.LastSQL(), err)
+		}
+		err = query.AddAll(
+			[]Data{{Field: "account", Value: "test1"}, {Field: "created_at", Value: Raw("NOW()")}},
+			[]Data{{Field: "account", Value: "test2"}, {Field: "created_at", Value: "2024-01-01"}},
+		)
+		if err == nil {
+			t.Fatalf("mixed raw insert all should fail")
+		}
+	})
+
+	t.Run("fan out shards", func(t *testing.T) {
+		var queries []string
+		defer useConnector("optimistic_shard", queryConnector{queries: &queries, affected: map[string]int64{"b_order_1": 1}})()
+		orderModel := &Model{
+			DBAlias:   "optimistic_shard",
+			TableName: "b_order",
+			Version:   "version",
+			Sharding: &Sharding{
+				Key:    "user_id",
+				Shards: []Shard{{TableName: "b_order_0"}, {TableName: "b_order_1"}},
+				FanOut: true,
+			},
+		}
+		rows, err := orderModel.Where("id = ?", 9).Update(Data{Field: "status", Value: 2}, Data{Field: "version", Value: 3})
+		if err != nil || rows != 1 || len(queries) != 2 {
+			t.Fatalf("fan out version fail : %d, %v, %q", rows, err, queries)
+		}
+		queries = nil
+		orderModel.Sharding.Shards[1].TableName = "b_order_2"
+		if _, err = orderModel.Where("id = ?", 9).Update(Data{Field: "status", Value: 2}, Data{Field: "version", Value: 3}); !errors.Is(err, ErrStaleObject) || len(queries) != 2 {
+			t.Fatalf("fan out stale fail : %v, %q", err, queries)
+		}
+	})
+}
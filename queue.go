@@ -0,0 +1,233 @@
+package mysqlgo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//任务状态
+const (
+	JobReady    = iota //等待执行
+	JobReserved        //已被取出，租约期内
+	JobDone            //已完成
+	JobDead            //超过最大重试次数，进入死信状态
+)
+
+//Queue 基于数据表的任务队列
+///多个消费者通过 SELECT ... FOR UPDATE SKIP LOCKED 并发取出任务，互不阻塞
+///数据表结构:
+///	CREATE TABLE `b_job` (
+///		`id` BIGINT UNSIGNED NOT NULL AUTO_INCREMENT,
+///		`queue` VARCHAR(64) NOT NULL,
+///		`payload` BLOB,
+///		`status` TINYINT NOT NULL DEFAULT 0,
+///		`attempts` INT NOT NULL DEFAULT 0,
+///		`available_at` BIGINT NOT NULL DEFAULT 0,
+///		`reserved_until` BIGINT NOT NULL DEFAULT 0,
+///		`last_error` TEXT,
+///		PRIMARY KEY (`id`),
+///		KEY `idx_dequeue` (`queue`, `status`, `available_at`)
+///	)
+type Queue struct {
+	Name              string        //队列名称
+	DBAlias           string        //数据库别名，默认为"default"
+	TableName         string        //数据表名
+	VisibilityTimeout time.Duration //租约时长，超时未确认的任务会被重新取出，默认30秒
+	MaxAttempts       int           //最大执行次数，默认3次
+}
+
+//Job 队列中的任务
+type Job struct {
+	ID       int64  `db:"id"`
+	Queue    string `db:"queue"`
+	Payload  []byte `db:"payload"`
+	Status   int    `db:"status"`
+	Attempts int    `db:"attempts"`
+}
+
+var jobFields = []string{"id", "queue", "payload", "status", "attempts"}
+
+//dequeueRetry 取出任务时，最多跳过的死信任务数量
+var dequeueRetry = 10
+
+//model 队列的数据表模型，与Dequeue的事务一样，别名未配置时返回错误，不回退到"default"
+func (q *Queue) model() *Model {
+	return &Model{
+		DBAlias:   q.DBAlias,
+		TableName: q.TableName,
+		options:   &option{strictAlias: true},
+	}
+}
+
+func (q *Queue) visibilityTimeout() time.Duration {
+	if q.VisibilityTimeout <= 0 {
+		return 30 * time.Second
+	}
+	return q.VisibilityTimeout
+}
+
+func (q *Queue) maxAttempts() int {
+	if q.MaxAttempts <= 0 {
+		return 3
+	}
+	return q.MaxAttempts
+}
+
+func queueTime(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
+
+//Enqueue 添加任务，返回任务ID
+///delay 延迟执行的时间
+func (q *Queue) Enqueue(payload []byte, delay ...time.Duration) (int64, error) {
+	availableAt := time.Now()
+	if len(delay) > 0 {
+		availableAt = availableAt.Add(delay[0])
+	}
+	return q.model().Add(q.enqueueData(payload, availableAt)...)
+}
+
+func (q *Queue) enqueueData(payload []byte, availableAt time.Time) []Data {
+	return []Data{
+		{Field: "queue", Value: q.Name},
+		{Field: "payload", Value: payload},
+		{Field: "status", Value: JobReady},
+		{Field: "attempts", Value: 0},
+		{Field: "available_at", Value: queueTime(availableAt)},
+	}
+}
+
+//Dequeue 取出一个任务，并在VisibilityTimeout内持有租约
+///队列为空时返回nil
+func (q *Queue) Dequeue() (*Job, error) {
+	var job *Job
+	err := Transaction(func(tx *Tx) error {
+		model := q.model().Tx(tx)
+		for i := 0; i < dequeueRetry; i++ {
+			now := time.Now()
+			candidate := &Job{}
+			err := q.dequeueQuery(model, now).Find(candidate)
+			if err != nil {
+				if errors.Is(err, ErrNoRows) {
+					return nil
+				}
+				return err
+			}
+			//租约过期且已达到最大执行次数的任务进入死信状态
+			if candidate.Status == JobReserved && candidate.Attempts >= q.maxAttempts() {
+				_, err = model.Where("id = ?", candidate.ID).Update(q.deadData("visibility timeout")...)
+				if err != nil {
+					return err
+				}
+				continue
+			}
+			_, err = model.Where("id = ?", candidate.ID).Update(q.leaseData(now)...)
+			if err != nil {
+				return err
+			}
+			candidate.Status = JobReserved
+			candidate.Attempts++
+			job = candidate
+			return nil
+		}
+		return nil
+	}, q.model().getDBAlias())
+	if err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+//dequeueQuery 取出可执行的任务：等待执行且已到执行时间，或租约已过期
+func (q *Queue) dequeueQuery(model *Model, now time.Time) *Model {
+	return model.Field(jobFields...).
+		Where("queue = ? AND ((status = ? AND available_at <= ?) OR (status = ? AND reserved_until <= ?))",
+			q.Name, JobReady, queueTime(now), JobReserved, queueTime(now)).
+		Order(Order{Field: "available_at"}, Order{Field: "id"}).
+		LockForUpdate(LockSkipLocked)
+}
+
+//leaseData 取得租约，attempts加1作为本次租约的标识
+func (q *Queue) leaseData(now time.Time) []Data {
+	return []Data{
+		{Field: "status", Value: JobReserved},
+		{Field: "attempts", Value: Raw("attempts + 1")},
+		{Field: "reserved_until", Value: queueTime(now.Add(q.visibilityTimeout()))},
+	}
+}
+
+func (q *Queue) deadData(reason string) []Data {
+	return []Data{
+		{Field: "status", Value: JobDead},
+		{Field: "last_error", Value: reason},
+	}
+}
+
+//Ack 确认任务已完成
+func (q *Queue) Ack(job *Job) error {
+	return q.release(job, "[Queue Ack]",
+		Data{Field: "status", Value: JobDone},
+	)
+}
+
+//Nack 任务执行失败，delay后重新执行
+///已达到最大执行次数的任务进入死信状态
+func (q *Queue) Nack(job *Job, reason string, delay ...time.Duration) error {
+	if job.Attempts >= q.maxAttempts() {
+		return q.release(job, "[Queue Nack]", q.deadData(reason)...)
+	}
+	availableAt := time.Now()
+	if len(delay) > 0 {
+		availableAt = availableAt.Add(delay[0])
+	}
+	return q.release(job, "[Queue Nack]",
+		Data{Field: "status", Value: JobReady},
+		Data{Field: "available_at", Value: queueTime(availableAt)},
+		Data{Field: "last_error", Value: reason},
+	)
+}
+
+//Extend 延长任务的租约
+func (q *Queue) Extend(job *Job, d time.Duration) error {
+	return q.release(job, "[Queue Extend]",
+		Data{Field: "reserved_until", Value: queueTime(time.Now().Add(d))},
+	)
+}
+
+//Retry 将死信任务重新放回队列
+func (q *Queue) Retry(id int64) error {
+	rows, err := q.retryQuery(q.model(), id).Update(
+		Data{Field: "status", Value: JobReady},
+		Data{Field: "attempts", Value: 0},
+		Data{Field: "available_at", Value: queueTime(time.Now())},
+	)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("[Queue Retry]: The dead job %d is not found", id)
+	}
+	return nil
+}
+
+//release 修改仍持有租约的任务，attempts作为租约的标识，租约已过期或被其他消费者取得后返回ErrLeaseLost
+func (q *Queue) release(job *Job, name string, datas ...Data) error {
+	rows, err := q.leaseQuery(q.model(), job, time.Now()).Update(datas...)
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return fmt.Errorf("%s: %w", name, ErrLeaseLost)
+	}
+	return nil
+}
+
+//leaseQuery 仍持有租约的任务，租约过期后即使还没有被其他消费者取得也不能再修改
+func (q *Queue) leaseQuery(model *Model, job *Job, now time.Time) *Model {
+	return model.Where("id = ? AND status = ? AND attempts = ? AND reserved_until > ?", job.ID, JobReserved, job.Attempts, queueTime(now))
+}
+
+func (q *Queue) retryQuery(model *Model, id int64) *Model {
+	return model.Where("id = ? AND queue = ? AND status = ?", id, q.Name, JobDead)
+}
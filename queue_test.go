@@ -0,0 +1,138 @@
+package mysqlgo
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestQueue(t *testing.T) {
+	t.Run("queue default", func(t *testing.T) {
+		queue := &Queue{
+			Name:      "mail",
+			TableName: "b_job",
+		}
+		if queue.visibilityTimeout() != 30*time.Second || queue.maxAttempts() != 3 {
+			t.Fatalf("queue default fail : %v, %d", queue.visibilityTimeout(), queue.maxAttempts())
+		}
+	})
+
+	t.Run("queue without connection", func(t *testing.T) {
+		queue := &Queue{
+			Name:      "mail",
+			DBAlias:   "queue_not_configured",
+			TableName: "b_job",
+		}
+		if _, err := queue.Dequeue(); !errors.Is(err, ErrAliasNotConfigured) {
+			t.Fatalf("dequeue fail : %v", err)
+		}
+		if _, err := queue.Enqueue([]byte("hi")); !errors.Is(err, ErrAliasNotConfigured) {
+			t.Fatalf("enqueue should not fall back to default : %v", err)
+		}
+	})
+
+	t.Run("expired lease", func(t *testing.T) {
+		var queries []string
+		defer useConnector("queue_lease", queryConnector{queries: &queries})()
+		queue := &Queue{
+			Name:      "mail",
+			DBAlias:   "queue_lease",
+			TableName: "b_job",
+		}
+		//租约过期的任务匹配0行，即使还没有被其他消费者取得
+		if err := queue.Ack(&Job{ID: 5, Attempts: 2}); !errors.Is(err, ErrLeaseLost) {
+			t.Fatalf("ack expired lease should fail : %v", err)
+		}
+		if len(queries) != 1 || !strings.Contains(queries[0], "reserved_until > ?") {
+			t.Fatalf("ack expired lease fail : %v", queries)
+		}
+	})
+
+	t.Run("queue sql", func(t *testing.T) {
+		queue := &Queue{
+			Name:      "mail",
+			TableName: "b_job",
+		}
+		now := time.Now()
+		job := &Job{ID: 5, Attempts: 2}
+		testCases := []struct {
+			name  string
+			query *Model
+			exec  func(m *Model) error
+			sql   string
+			args  []interface{}
+		}{
+			{
+				name:  "enqueue",
+				query: queue.model().FetchSQL(),
+				exec: func(m *Model) error {
+					_, err := m.Add(queue.enqueueData([]byte("hi"), now)...)
+					return err
+				},
+				sql:  "INSERT INTO b_job(`queue`,`payload`,`status`,`attempts`,`available_at`) VALUE(?,?,?,?,?)",
+				args: []interface{}{"mail", []byte("hi"), JobReady, 0, queueTime(now)},
+			},
+			{
+				name:  "dequeue",
+				query: queue.dequeueQuery(queue.model().FetchSQL().Tx(&Tx{}), now),
+				exec: func(m *Model) error {
+					return m.Find(&Job{})
+				},
+				sql:  "SELECT `id`,`queue`,`payload`,`status`,`attempts` FROM b_job WHERE queue = ? AND ((status = ? AND available_at <= ?) OR (status = ? AND reserved_until <= ?))  ORDER BY  `available_at` asc, `id` asc  Limit 1  FOR UPDATE SKIP LOCKED ",
+				args: []interface{}{"mail", JobReady, queueTime(now), JobReserved, queueTime(now)},
+			},
+			{
+				name:  "lease",
+				query: queue.model().FetchSQL().Where("id = ?", job.ID),
+				exec: func(m *Model) error {
+					_, err := m.Update(queue.leaseData(now)...)
+					return err
+				},
+				sql:  "UPDATE b_job SET  `status` = ? , `attempts` = attempts + 1 , `reserved_until` = ?  WHERE id = ?",
+				args: []interface{}{JobReserved, queueTime(now.Add(30 * time.Second)), job.ID},
+			},
+			{
+				name:  "ack",
+				query: queue.leaseQuery(queue.model().FetchSQL(), job, now),
+				exec: func(m *Model) error {
+					_, err := m.Update(Data{Field: "status", Value: JobDone})
+					return err
+				},
+				sql:  "UPDATE b_job SET  `status` = ?  WHERE id = ? AND status = ? AND attempts = ? AND reserved_until > ?",
+				args: []interface{}{JobDone, job.ID, JobReserved, job.Attempts, queueTime(now)},
+			},
+			{
+				name:  "dead letter",
+				query: queue.leaseQuery(queue.model().FetchSQL(), job, now),
+				exec: func(m *Model) error {
+					_, err := m.Update(queue.deadData("timeout")...)
+					return err
+				},
+				sql:  "UPDATE b_job SET  `status` = ? , `last_error` = ?  WHERE id = ? AND status = ? AND attempts = ? AND reserved_until > ?",
+				args: []interface{}{JobDead, "timeout", job.ID, JobReserved, job.Attempts, queueTime(now)},
+			},
+			{
+				name:  "retry",
+				query: queue.retryQuery(queue.model().FetchSQL(), job.ID),
+				exec: func(m *Model) error {
+					_, err := m.Update(Data{Field: "status", Value: JobReady})
+					return err
+				},
+				sql:  "UPDATE b_job SET  `status` = ?  WHERE id = ? AND queue = ? AND status = ?",
+				args: []interface{}{JobReady, job.ID, "mail", JobDead},
+			},
+		}
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				if err := tc.exec(tc.query); err != nil {
+					t.Fatalf("queue sql fail : %v", err)
+				}
+				if tc.query.LastSQL() != tc.sql || !reflect.DeepEqual(tc.query.LastArgs(), tc.args) {
+					t.Fatalf("queue sql fail : %q %v", tc.query.LastSQL(), tc.query.LastArgs())
+				}
+			})
+		}
+	})
+}
@@ -0,0 +1,325 @@
+package mysqlgo
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+//关联关系类型
+const (
+	HasOne     = iota //一对一，关联表中的外键指向当前表
+	HasMany           //一对多，关联表中的外键指向当前表
+	BelongsTo         //从属，当前表中的外键指向关联表
+	ManyToMany        //多对多，通过中间表关联
+)
+
+//Relation 表之间的关联关系
+type Relation struct {
+	Type            int    //关联关系类型
+	Model           *Model //关联的表模型，关联表上声明的Relations可用于嵌套预加载
+	ForeignKey      string //HasOne/HasMany为关联表中指向当前表的字段，BelongsTo为当前表中指向关联表的字段
+	LocalKey        string //HasOne/HasMany/ManyToMany为当前表的键，BelongsTo为关联表的键，默认为id
+	Pivot           string //ManyToMany的中间表
+	PivotForeignKey string //中间表中指向当前表的字段
+	PivotRelatedKey string //中间表中指向关联表的字段
+	RelatedKey      string //ManyToMany关联表的键，默认为id
+	Field           string //结构体中存放关联数据的字段名，默认为与关联名相同(不区分大小写)的字段
+}
+
+type pivotRow struct {
+	Parent  interface{} `db:"pivot_parent"`
+	Related interface{} `db:"pivot_related"`
+}
+
+//With 预加载关联数据，嵌套关联使用"."分隔，如 With("orders.items")
+///每一层关联只执行一次 WHERE key IN (...) 查询
+func (m *Model) With(relations ...string) *Model {
+	q := m.clone()
+	q.options.with = append(q.options.with, relations...)
+	return q
+}
+
+//eagerLoad 为查询结果加载关联数据
+func (m *Model) eagerLoad(options *option, dest interface{}) error {
+	if len(options.with) == 0 {
+		return nil
+	}
+	return m.loadRelations(options, collectStructs(reflect.ValueOf(dest)), options.with)
+}
+
+func (m *Model) loadRelations(options *option, parents []reflect.Value, paths []string) error {
+	if len(parents) == 0 || len(paths) == 0 {
+		return nil
+	}
+	var names []string
+	nested := make(map[string][]string, 0)
+	for _, path := range paths {
+		parts := strings.SplitN(path, ".", 2)
+		if _, ok := nested[parts[0]]; !ok {
+			names = append(names, parts[0])
+			nested[parts[0]] = nil
+		}
+		if len(parts) > 1 {
+			nested[parts[0]] = append(nested[parts[0]], parts[1])
+		}
+	}
+	for _, name := range names {
+		relation, ok := m.Relations[name]
+		if !ok || relation.Model == nil {
+			return fmt.Errorf("[Model With]: The relation `%s` is not defined on %s", name, m.getTableName())
+		}
+		if err := relation.load(name, options, parents, nested[name]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *Relation) load(name string, options *option, parents []reflect.Value, nested []string) error {
+	parentType := parents[0].Type()
+	field, ok := relationField(parentType, name, r.Field)
+	if !ok {
+		return fmt.Errorf("[Model With]: The field of relation `%s` is not found in %s", name, parentType)
+	}
+	childType := field.Type
+	if childType.Kind() == reflect.Slice {
+		childType = childType.Elem()
+	}
+	for childType.Kind() == reflect.Ptr {
+		childType = childType.Elem()
+	}
+	parentSchema, err := schemaOfType(parentType)
+	if err != nil {
+		return err
+	}
+	childSchema, err := schemaOfType(childType)
+	if err != nil {
+		return err
+	}
+
+	//parentKey为当前表中用于匹配的字段，childKey为关联数据中用于匹配的字段
+	var parentKey, childKey string
+	switch r.Type {
+	case HasOne, HasMany:
+		parentKey, childKey = defaultKey(r.LocalKey), r.ForeignKey
+	case BelongsTo:
+		parentKey, childKey = r.ForeignKey, defaultKey(r.LocalKey)
+	case ManyToMany:
+		parentKey, childKey = defaultKey(r.LocalKey), defaultKey(r.RelatedKey)
+	default:
+		return fmt.Errorf("[Model With]: The type of relation `%s` is invalid", name)
+	}
+	if parentKey == "" || childKey == "" {
+		return fmt.Errorf("[Model With]: The ForeignKey of relation `%s` is nil", name)
+	}
+	keys, err := columnValues(parentSchema, parents, parentKey)
+	if err != nil {
+		return err
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	//多对多先查询中间表，得到关联表的键
+	pivot := make(map[string][]string, 0)
+	relatedKeys := keys
+	if r.Type == ManyToMany {
+		var rows []pivotRow
+		//中间表与关联表使用相同的数据库及表前缀
+		pivotModel := &Model{DBAlias: r.Model.DBAlias, TableName: r.Pivot, Prefix: r.Model.Prefix}
+		err = options.bind(pivotModel).
+			Field(fmt.Sprintf("%s AS pivot_parent", r.PivotForeignKey), fmt.Sprintf("%s AS pivot_related", r.PivotRelatedKey)).
+			Where(fmt.Sprintf("%s IN (%s)", r.PivotForeignKey, placeholders(len(keys))), keys...).
+			Select(&rows)
+		if err != nil {
+			return err
+		}
+		relatedKeys = nil
+		seen := make(map[string]bool, 0)
+		for _, row := range rows {
+			parent, related := keyString(row.Parent), keyString(row.Related)
+			pivot[parent] = append(pivot[parent], related)
+			if !seen[related] {
+				seen[related] = true
+				relatedKeys = append(relatedKeys, row.Related)
+			}
+		}
+		if len(relatedKeys) == 0 {
+			return nil
+		}
+	}
+
+	children := reflect.New(reflect.SliceOf(childType))
+	query := options.bind(r.Model).Where(fmt.Sprintf("%s IN (%s)", childKey, placeholders(len(relatedKeys))), relatedKeys...)
+	if query.options.field == "" {
+		var fields []string
+		for _, col := range childSchema.columns {
+			fields = append(fields, col.name)
+		}
+		query = query.Field(fields...)
+	}
+	if err = query.Select(children.Interface()); err != nil {
+		return err
+	}
+	childValues := collectStructs(children)
+	if err = r.Model.loadRelations(options, childValues, nested); err != nil {
+		return err
+	}
+
+	grouped := make(map[string][]reflect.Value, 0)
+	for _, child := range childValues {
+		key, err := columnKey(childSchema, child, childKey)
+		if err != nil {
+			return err
+		}
+		grouped[key] = append(grouped[key], child)
+	}
+	for _, parent := range parents {
+		key, err := columnKey(parentSchema, parent, parentKey)
+		if err != nil {
+			return err
+		}
+		matched := grouped[key]
+		if r.Type == ManyToMany {
+			matched = nil
+			for _, related := range pivot[key] {
+				matched = append(matched, grouped[related]...)
+			}
+		}
+		assignRelation(parent.FieldByIndex(field.Index), matched)
+	}
+	return nil
+}
+
+//relationField 查找存放关联数据的结构体字段
+func relationField(typ reflect.Type, name string, fieldName string) (reflect.StructField, bool) {
+	if fieldName != "" {
+		return typ.FieldByName(fieldName)
+	}
+	return typ.FieldByNameFunc(func(field string) bool {
+		return strings.EqualFold(field, name)
+	})
+}
+
+//assignRelation 将关联数据写入字段，支持 T、*T、[]T、[]*T
+func assignRelation(field reflect.Value, matched []reflect.Value) {
+	typ := field.Type()
+	if typ.Kind() == reflect.Slice {
+		slice := reflect.MakeSlice(typ, 0, len(matched))
+		for _, child := range matched {
+			slice = reflect.Append(slice, adaptValue(child, typ.Elem()))
+		}
+		field.Set(slice)
+		return
+	}
+	if len(matched) == 0 {
+		field.Set(reflect.Zero(typ))
+		return
+	}
+	field.Set(adaptValue(matched[0], typ))
+}
+
+func adaptValue(child reflect.Value, typ reflect.Type) reflect.Value {
+	if typ.Kind() == reflect.Ptr {
+		return child.Addr()
+	}
+	return child
+}
+
+//collectStructs 取出结构体、结构体切片中可寻址的结构体
+func collectStructs(v reflect.Value) []reflect.Value {
+	for v.Kind() == reflect.Ptr || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	switch v.Kind() {
+	case reflect.Struct:
+		return []reflect.Value{v}
+	case reflect.Slice:
+		var values []reflect.Value
+		for i := 0; i < v.Len(); i++ {
+			values = append(values, collectStructs(v.Index(i).Addr())...)
+		}
+		return values
+	}
+	return nil
+}
+
+//columnValues 取出字段的值并去重
+func columnValues(s *schema, values []reflect.Value, name string) ([]interface{}, error) {
+	var keys []interface{}
+	seen := make(map[string]bool, 0)
+	for _, value := range values {
+		key, err := columnKey(s, value, name)
+		if err != nil {
+			return nil, err
+		}
+		if key == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+		keys = append(keys, columnValue(s, value, name))
+	}
+	return keys, nil
+}
+
+func columnValue(s *schema, value reflect.Value, name string) interface{} {
+	for _, col := range s.columns {
+		if col.name == name {
+			return value.FieldByIndex(col.index).Interface()
+		}
+	}
+	return nil
+}
+
+func columnKey(s *schema, value reflect.Value, name string) (string, error) {
+	for _, col := range s.columns {
+		if col.name == name {
+			return keyString(value.FieldByIndex(col.index).Interface()), nil
+		}
+	}
+	return "", fmt.Errorf("[Model With]: The column `%s` is not found in %s", name, value.Type())
+}
+
+//keyString 将键统一转换为字符串，用于匹配不同类型的键值
+func keyString(value interface{}) string {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return ""
+		}
+		value = v
+	}
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case []byte:
+		return string(v)
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return ""
+		}
+		rv = rv.Elem()
+	}
+	return fmt.Sprint(rv.Interface())
+}
+
+func placeholders(n int) string {
+	if n <= 0 {
+		return ""
+	}
+	return strings.TrimSuffix(strings.Repeat("?,", n), ",")
+}
+
+func defaultKey(key string) string {
+	if key == "" {
+		return "id"
+	}
+	return key
+}
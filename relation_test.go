@@ -0,0 +1,203 @@
+package mysqlgo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//queryConnector 记录执行的语句，并按数据表返回预设的结果及影响的行数
+type queryConnector struct {
+	queries  *[]string
+	results  map[string][][]driver.Value
+	affected map[string]int64
+}
+
+type queryConn struct {
+	queryConnector
+}
+
+type queryRows struct {
+	columns []string
+	values  [][]driver.Value
+}
+
+func (c queryConnector) Connect(context.Context) (driver.Conn, error) { return queryConn{c}, nil }
+func (queryConnector) Driver() driver.Driver                          { return nil }
+func (queryConn) Prepare(string) (driver.Stmt, error)                 { return nil, driver.ErrSkip }
+func (queryConn) Close() error                                        { return nil }
+func (queryConn) Begin() (driver.Tx, error)                           { return nil, driver.ErrSkip }
+
+func (c queryConn) QueryContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Rows, error) {
+	*c.queries = append(*c.queries, query)
+	start := strings.Index(query, "SELECT ") + len("SELECT ")
+	var columns []string
+	for _, field := range strings.Split(query[start:strings.Index(query, " FROM ")], ",") {
+		parts := strings.Fields(field)
+		columns = append(columns, strings.Trim(parts[len(parts)-1], "`"))
+	}
+	table := strings.Fields(query[strings.Index(query, " FROM ")+len(" FROM "):])[0]
+	return &queryRows{columns: columns, values: c.results[table]}, nil
+}
+
+func (c queryConn) ExecContext(ctx context.Context, query string, args []driver.NamedValue) (driver.Result, error) {
+	*c.queries = append(*c.queries, query)
+	return driver.RowsAffected(c.affected[strings.Fields(query)[1]]), nil
+}
+
+//useConnector 将别名注册为测试连接，返回注销的函数
+func useConnector(alias string, connector queryConnector) func() {
+	dbMu.Lock()
+	dbConfigs[alias] = &dbConfig{db: sqlx.NewDb(sql.OpenDB(connector), driverName)}
+	dbMu.Unlock()
+	return func() {
+		dbMu.Lock()
+		delete(dbConfigs, alias)
+		dbMu.Unlock()
+	}
+}
+
+func (r *queryRows) Columns() []string { return r.columns }
+func (r *queryRows) Close() error      { return nil }
+func (r *queryRows) Next(dest []driver.Value) error {
+	if len(r.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, r.values[0])
+	r.values = r.values[1:]
+	return nil
+}
+
+type orderItem struct {
+	ID      int64 `db:"id"`
+	OrderID int64 `db:"order_id"`
+}
+
+type userOrder struct {
+	ID     int64 `db:"id"`
+	UserID int64 `db:"user_id"`
+	Items  []orderItem
+}
+
+type orderUser struct {
+	ID     int64 `db:"id"`
+	Orders []*userOrder
+	First  *userOrder
+}
+
+type userRole struct {
+	ID   int64  `db:"id"`
+	Name string `db:"name"`
+}
+
+type roleUser struct {
+	ID     int64 `db:"id"`
+	Orders []userOrder
+	Roles  []userRole
+}
+
+func TestRelation(t *testing.T) {
+	t.Run("relation field is not column", func(t *testing.T) {
+		s, err := schemaOfType(reflect.TypeOf(orderUser{}))
+		if err != nil {
+			t.Fatalf("schema fail : %v", err)
+		}
+		if len(s.columns) != 1 || s.columns[0].name != "id" {
+			t.Fatalf("columns fail : %+v", s.columns)
+		}
+	})
+
+	t.Run("assign relation", func(t *testing.T) {
+		users := []orderUser{{ID: 1}, {ID: 2}}
+		orders := []userOrder{{ID: 10, UserID: 1}, {ID: 11, UserID: 1}, {ID: 12, UserID: 2}}
+		parents := collectStructs(reflect.ValueOf(&users))
+		children := collectStructs(reflect.ValueOf(&orders))
+		if len(parents) != 2 || len(children) != 3 {
+			t.Fatalf("collect structs fail : %d, %d", len(parents), len(children))
+		}
+		field, ok := relationField(reflect.TypeOf(orderUser{}), "orders", "")
+		if !ok {
+			t.Fatalf("relation field not found")
+		}
+		assignRelation(parents[0].FieldByIndex(field.Index), children[:2])
+		assignRelation(parents[1].FieldByIndex(field.Index), children[2:])
+		if len(users[0].Orders) != 2 || users[0].Orders[1].ID != 11 || users[1].Orders[0].ID != 12 {
+			t.Fatalf("assign has many fail : %+v", users)
+		}
+		field, _ = relationField(reflect.TypeOf(orderUser{}), "first", "")
+		assignRelation(parents[0].FieldByIndex(field.Index), children[:1])
+		if users[0].First == nil || users[0].First.ID != 10 {
+			t.Fatalf("assign has one fail : %+v", users[0].First)
+		}
+	})
+
+	t.Run("key string", func(t *testing.T) {
+		testCases := []struct {
+			in  interface{}
+			out string
+		}{
+			{in: int64(1), out: "1"},
+			{in: []byte("1"), out: "1"},
+			{in: uint8(1), out: "1"},
+			{in: nil, out: ""},
+		}
+		for _, testCase := range testCases {
+			if out := keyString(testCase.in); out != testCase.out {
+				t.Fatalf("key string fail case : %v, out : %s", testCase, out)
+			}
+		}
+	})
+
+	t.Run("undefined relation", func(t *testing.T) {
+		userModel := &Model{TableName: "b_user"}
+		users := []orderUser{{ID: 1}}
+		if err := userModel.loadRelations(&option{}, collectStructs(reflect.ValueOf(&users)), []string{"orders"}); err == nil {
+			t.Fatalf("undefined relation should fail")
+		}
+	})
+
+	t.Run("eager load sql", func(t *testing.T) {
+		var queries []string
+		connector := queryConnector{queries: &queries, results: map[string][][]driver.Value{
+			"b_order":         {{int64(10), int64(1)}, {int64(11), int64(2)}},
+			"b_order_item":    {{int64(100), int64(10)}},
+			"app_b_user_role": {{int64(1), int64(7)}, {int64(2), int64(7)}},
+			"app_b_role":      {{int64(7), "admin"}},
+		}}
+		defer useConnector("relation_test", connector)()
+
+		itemModel := &Model{DBAlias: "relation_test", TableName: "b_order_item"}
+		orderModel := &Model{DBAlias: "relation_test", TableName: "b_order", Relations: map[string]*Relation{
+			"items": {Type: HasMany, Model: itemModel, ForeignKey: "order_id"},
+		}}
+		roleModel := &Model{DBAlias: "relation_test", TableName: "b_role", Prefix: "app_"}
+		userModel := &Model{DBAlias: "relation_test", TableName: "b_user", Relations: map[string]*Relation{
+			"orders": {Type: HasMany, Model: orderModel, ForeignKey: "user_id"},
+			"roles": {Type: ManyToMany, Model: roleModel, Pivot: "b_user_role",
+				PivotForeignKey: "user_id", PivotRelatedKey: "role_id"},
+		}}
+		users := []roleUser{{ID: 1}, {ID: 2}}
+		options, _ := userModel.With("orders.items", "roles").prepare()
+		if err := userModel.eagerLoad(options, &users); err != nil {
+			t.Fatalf("eager load fail : %v", err)
+		}
+		want := []string{
+			"SELECT `id`,`user_id` FROM b_order WHERE user_id IN (?,?)  ",
+			"SELECT `id`,`order_id` FROM b_order_item WHERE order_id IN (?,?)  ",
+			"SELECT `user_id` AS `pivot_parent`,`role_id` AS `pivot_related` FROM app_b_user_role WHERE user_id IN (?,?)  ",
+			"SELECT `id`,`name` FROM app_b_role WHERE id IN (?)  ",
+		}
+		if !reflect.DeepEqual(queries, want) {
+			t.Fatalf("eager load sql fail : %q", queries)
+		}
+		if len(users[0].Orders) != 1 || len(users[0].Orders[0].Items) != 1 || len(users[1].Roles) != 1 || users[1].Roles[0].Name != "admin" {
+			t.Fatalf("eager load assign fail : %+v", users)
+		}
+	})
+}
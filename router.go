@@ -0,0 +1,173 @@
+package mysqlgo
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+//TenantRouter 按租户将未指定DBAlias的Model路由到独立的数据库
+type TenantRouter struct {
+	//Resolve 返回租户使用的数据库别名，返回空字符串时使用"default"
+	Resolve func(tenantID interface{}) string
+	//Provider 获取尚未连接的租户数据库配置，为nil时别名必须已经通过Connect注册
+	Provider func(ctx context.Context, tenantID interface{}, alias string) (*Config, error)
+	//IdleTimeout 由Provider连接的数据库空闲超过该时长后释放，为0时不释放
+	IdleTimeout time.Duration
+}
+
+var (
+	tenantRouter *TenantRouter
+	routerMu     sync.RWMutex
+	routerStop   chan struct{}
+	//connectMu 只保护connecting，不在连接期间持有
+	connectMu sync.Mutex
+	//connecting 正在连接的别名，同一个别名只连接一次，不同别名并行连接
+	connecting = map[string]*connectCall{}
+)
+
+//connectCall 一次按需连接，同一个别名的其他请求等待并共用结果
+type connectCall struct {
+	done chan struct{}
+	err  error
+}
+
+//SetTenantRouter 设置租户路由，传入nil时取消路由
+func SetTenantRouter(router *TenantRouter) {
+	routerMu.Lock()
+	defer routerMu.Unlock()
+	if routerStop != nil {
+		close(routerStop)
+		routerStop = nil
+	}
+	tenantRouter = router
+	if router != nil && router.IdleTimeout > 0 {
+		routerStop = make(chan struct{})
+		go evictIdleTenants(router.IdleTimeout, routerStop)
+	}
+}
+
+//resolveAlias 获取本次操作使用的数据库别名
+///Model指定了DBAlias时直接使用，否则按上下文中的租户路由
+func (m *Model) resolveAlias(options *option) (string, error) {
+	alias, tenantID, router := m.routeAlias(options)
+	if router == nil {
+		return alias, nil
+	}
+	if err := router.connect(options.context(), tenantID, alias); err != nil {
+		return "", err
+	}
+	return alias, nil
+}
+
+//routeAlias 获取本次操作路由到的数据库别名，不连接数据库
+///路由到租户数据库时同时返回租户ID及使用的路由，否则路由为nil
+func (m *Model) routeAlias(options *option) (string, interface{}, *TenantRouter) {
+	if m.DBAlias != "" {
+		return m.DBAlias, nil, nil
+	}
+	routerMu.RLock()
+	router := tenantRouter
+	routerMu.RUnlock()
+	if router == nil || router.Resolve == nil || options == nil {
+		return m.getDBAlias(), nil, nil
+	}
+	tenantID, ok := TenantFromContext(options.ctx)
+	if !ok {
+		return m.getDBAlias(), nil, nil
+	}
+	alias := router.Resolve(tenantID)
+	if alias == "" {
+		return m.getDBAlias(), nil, nil
+	}
+	return alias, tenantID, router
+}
+
+//connect 租户数据库未连接时通过Provider获取配置并连接
+func (router *TenantRouter) connect(ctx context.Context, tenantID interface{}, alias string) error {
+	if isConnected(alias) {
+		return nil
+	}
+	if router.Provider == nil {
+		return &AliasError{Alias: alias}
+	}
+	connectMu.Lock()
+	if call, ok := connecting[alias]; ok {
+		connectMu.Unlock()
+		select {
+		case <-call.done:
+			return call.err
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	call := &connectCall{done: make(chan struct{})}
+	connecting[alias] = call
+	connectMu.Unlock()
+
+	call.err = router.dial(ctx, tenantID, alias)
+	connectMu.Lock()
+	delete(connecting, alias)
+	connectMu.Unlock()
+	close(call.done)
+	return call.err
+}
+
+//dial 通过Provider获取配置并连接，上一次连接在检查之后完成时不再重复连接
+func (router *TenantRouter) dial(ctx context.Context, tenantID interface{}, alias string) error {
+	if isConnected(alias) {
+		return nil
+	}
+	config, err := router.Provider(ctx, tenantID, alias)
+	if err != nil {
+		return fmt.Errorf("[Config DB]: The tenant `%v` provider : %w", tenantID, err)
+	}
+	if config == nil {
+		return &AliasError{Alias: alias}
+	}
+	config.Alias = alias
+	if err = Connect(config); err != nil {
+		return err
+	}
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	if dbc, ok := dbConfigs[alias]; ok {
+		dbc.lazy = true
+		dbc.lastUsed = time.Now()
+	}
+	return nil
+}
+
+func isConnected(alias string) bool {
+	dbMu.RLock()
+	defer dbMu.RUnlock()
+	dbc, ok := dbConfigs[alias]
+	return ok && !dbc.closed()
+}
+
+func evictIdleTenants(timeout time.Duration, stop chan struct{}) {
+	ticker := time.NewTicker(timeout / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			evictTenants(now, timeout)
+		}
+	}
+}
+
+//evictTenants 释放空闲超时的租户数据库，下次访问时重新连接
+///仍有连接在使用(如执行时间超过IdleTimeout的事务或查询)时不释放
+func evictTenants(now time.Time, timeout time.Duration) {
+	dbMu.Lock()
+	defer dbMu.Unlock()
+	for alias, dbc := range dbConfigs {
+		if dbc.lazy && now.Sub(dbc.lastUsed) > timeout && !dbc.inUse() {
+			dbc.close()
+			delete(dbConfigs, alias)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+package mysqlgo
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//stubConnector 不需要数据库的连接，用于测试连接池的状态
+type stubConnector struct{}
+
+type stubConn struct{}
+
+func (stubConnector) Connect(context.Context) (driver.Conn, error) { return stubConn{}, nil }
+func (stubConnector) Driver() driver.Driver                        { return nil }
+func (stubConn) Prepare(string) (driver.Stmt, error)               { return nil, errors.New("not supported") }
+func (stubConn) Close() error                                      { return nil }
+func (stubConn) Begin() (driver.Tx, error)                         { return nil, errors.New("not supported") }
+
+func TestTenantRouter(t *testing.T) {
+	defer SetTenantRouter(nil)
+
+	t.Run("resolve alias", func(t *testing.T) {
+		provided := 0
+		SetTenantRouter(&TenantRouter{
+			Resolve: func(tenantID interface{}) string {
+				if tenantID == 1 {
+					return ""
+				}
+				return fmt.Sprintf("tenant_%v", tenantID)
+			},
+			Provider: func(ctx context.Context, tenantID interface{}, alias string) (*Config, error) {
+				provided++
+				return nil, errors.New("tenant config is not found")
+			},
+		})
+		orderModel := &Model{TableName: "b_order"}
+		testCases := []struct {
+			model *Model
+			ctx   context.Context
+			alias string
+			err   bool
+		}{
+			{model: orderModel, ctx: context.Background(), alias: "default"},
+			{model: orderModel, ctx: WithTenant(context.Background(), 1), alias: "default"},
+			{model: &Model{TableName: "b_order", DBAlias: "master"}, ctx: WithTenant(context.Background(), 2), alias: "master"},
+			{model: orderModel, ctx: WithTenant(context.Background(), 2), err: true},
+		}
+		for _, testCase := range testCases {
+			query := testCase.model.WithContext(testCase.ctx)
+			alias, err := query.resolveAlias(query.options)
+			if (err != nil) != testCase.err || alias != testCase.alias {
+				t.Fatalf("resolve alias fail case : %v, alias : %s, err : %v", testCase.alias, alias, err)
+			}
+		}
+		if provided != 1 {
+			t.Fatalf("provider should be called once : %d", provided)
+		}
+	})
+
+	t.Run("tenant prefix", func(t *testing.T) {
+		var queries []string
+		defer useConnector("tenant_3", queryConnector{queries: &queries})()
+		dbMu.Lock()
+		dbConfigs["tenant_3"].prefix = "t3_"
+		dbMu.Unlock()
+		SetTenantRouter(&TenantRouter{
+			Resolve: func(tenantID interface{}) string {
+				return fmt.Sprintf("tenant_%v", tenantID)
+			},
+		})
+		orderModel := &Model{TableName: "order"}
+		query := orderModel.WithContext(WithTenant(context.Background(), 3)).Field("id").Where("__PREFIX__order.id = ?", 1)
+		var orders []struct {
+			ID int `db:"id"`
+		}
+		if err := query.Select(&orders); err != nil {
+			t.Fatalf("tenant prefix fail : %v", err)
+		}
+		if len(queries) != 1 || queries[0] != "SELECT `id` FROM t3_order WHERE t3_order.id = ?  " {
+			t.Fatalf("tenant prefix fail : %q", queries)
+		}
+	})
+
+	t.Run("connect per alias", func(t *testing.T) {
+		entered := make(chan struct{})
+		release := make(chan struct{})
+		var provided int32
+		router := &TenantRouter{
+			Provider: func(ctx context.Context, tenantID interface{}, alias string) (*Config, error) {
+				if tenantID == "slow" {
+					atomic.AddInt32(&provided, 1)
+					close(entered)
+					<-release
+				}
+				return nil, fmt.Errorf("tenant %v is not found", tenantID)
+			},
+		}
+		done := make(chan error, 1)
+		go func() { done <- router.connect(context.Background(), "slow", "tenant_slow") }()
+		<-entered
+		//其他租户不需要等待正在连接的租户
+		if err := router.connect(context.Background(), "fast", "tenant_fast"); err == nil {
+			t.Fatalf("connect fast tenant should fail")
+		}
+		//同一个别名等待正在进行的连接，不重复调用Provider
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if err := router.connect(ctx, "slow", "tenant_slow"); !errors.Is(err, context.Canceled) {
+			t.Fatalf("connect same alias should wait : %v", err)
+		}
+		close(release)
+		if err := <-done; err == nil {
+			t.Fatalf("connect slow tenant should fail")
+		}
+		if atomic.LoadInt32(&provided) != 1 {
+			t.Fatalf("provider should be called once for the same alias : %d", provided)
+		}
+	})
+
+	t.Run("evict idle tenant", func(t *testing.T) {
+		dbMu.Lock()
+		dbConfigs["tenant_idle"] = &dbConfig{lazy: true, isClose: true, lastUsed: time.Now().Add(-time.Hour)}
+		dbConfigs["tenant_busy"] = &dbConfig{lazy: true, isClose: true, lastUsed: time.Now()}
+		dbMu.Unlock()
+		evictTenants(time.Now(), time.Minute)
+		if isConnected("tenant_idle") {
+			t.Fatalf("idle tenant should be evicted")
+		}
+		dbMu.Lock()
+		_, ok := dbConfigs["tenant_busy"]
+		delete(dbConfigs, "tenant_busy")
+		dbMu.Unlock()
+		if !ok {
+			t.Fatalf("busy tenant should not be evicted")
+		}
+	})
+
+	t.Run("keep tenant in use", func(t *testing.T) {
+		db := sqlx.NewDb(sql.OpenDB(stubConnector{}), driverName)
+		conn, err := db.Conn(context.Background())
+		if err != nil {
+			t.Fatalf("stub conn fail : %v", err)
+		}
+		dbMu.Lock()
+		dbConfigs["tenant_running"] = &dbConfig{db: db, lazy: true, lastUsed: time.Now().Add(-time.Hour)}
+		dbMu.Unlock()
+		evictTenants(time.Now(), time.Minute)
+		if !isConnected("tenant_running") {
+			t.Fatalf("tenant in use should not be evicted")
+		}
+		conn.Close()
+		evictTenants(time.Now(), time.Minute)
+		if isConnected("tenant_running") {
+			t.Fatalf("idle tenant should be evicted after the connection is released")
+		}
+	})
+}
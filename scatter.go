@@ -0,0 +1,410 @@
+package mysqlgo
+
+import (
+	"bytes"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math/big"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+//scatter 在多个分片上并发执行，并发数不超过Sharding.Concurrency
+func (m *Model) scatter(targets []*Model, fn func(i int, target *Model)) {
+	concurrency := m.Sharding.Concurrency
+	if concurrency <= 0 {
+		concurrency = 8
+	}
+	sem := make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, target *Model) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			fn(i, target)
+		}(i, target)
+	}
+	wg.Wait()
+}
+
+func (m *Model) shardFind(dest interface{}) error {
+	targets, err := m.shardTargets("[Model Find]")
+	if err != nil {
+		return m.record("", err)
+	}
+	if len(targets) == 1 {
+		targets[0].Find(dest)
+		return m.recordShards(targets)
+	}
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() {
+		return m.record("", errors.New("[Model Find]: The dest must be a pointer"))
+	}
+	//在全部分片上各取一条，按排序合并后取第一条
+	rows := reflect.New(reflect.SliceOf(rv.Elem().Type()))
+	if err = m.gather(targets, Limit{Offset: 1}, rows); err != nil {
+		return err
+	}
+	if rows.Elem().Len() == 0 {
+		return m.recordArgs(m.LastSQL(), m.LastArgs(), fmt.Errorf("[Model Find]: %w", &classifiedError{kind: ErrNoRows, err: sql.ErrNoRows}))
+	}
+	rv.Elem().Set(rows.Elem().Index(0))
+	return nil
+}
+
+func (m *Model) shardSelect(dest interface{}) error {
+	targets, err := m.shardTargets("[Model Select]")
+	if err != nil {
+		return m.record("", err)
+	}
+	if len(targets) == 1 {
+		targets[0].Select(dest)
+		return m.recordShards(targets)
+	}
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Ptr || rv.Elem().Kind() != reflect.Slice {
+		return m.record("", errors.New("[Model Select]: The dest must be a pointer to slice"))
+	}
+	var limit Limit
+	if m.options != nil {
+		limit = m.options.limit
+	}
+	return m.gather(targets, limit, rv)
+}
+
+//gather 在全部分片上并发查询，按Order合并结果并应用全局的Limit
+///每个分片查询前 offset+length 条，合并排序后再跳过offset条
+///带有Group的查询只合并各分片的分组结果，不会再次聚合
+func (m *Model) gather(targets []*Model, limit Limit, dest reflect.Value) error {
+	sliceType := dest.Elem().Type()
+	var offset, length int
+	if limit.Offset > 0 && limit.Length > 0 {
+		offset, length = limit.Offset, limit.Length
+	} else if limit.Offset > 0 {
+		length = limit.Offset
+	}
+	if length > 0 {
+		for i, target := range targets {
+			targets[i] = target.Limit(Limit{Offset: offset + length})
+		}
+	}
+	var orders []Order
+	if m.options != nil {
+		orders = m.options.order
+	}
+	less, err := orderLess(sliceType.Elem(), orders)
+	if err != nil {
+		return m.record("", err)
+	}
+
+	parts := make([]reflect.Value, len(targets))
+	m.scatter(targets, func(i int, target *Model) {
+		part := reflect.New(sliceType)
+		target.Select(part.Interface())
+		parts[i] = part.Elem()
+	})
+	if err = m.recordShards(targets); err != nil {
+		return err
+	}
+
+	result := reflect.MakeSlice(sliceType, 0, 0)
+	for _, part := range parts {
+		result = reflect.AppendSlice(result, part)
+	}
+	if less != nil {
+		sort.SliceStable(result.Interface(), func(i, j int) bool {
+			return less(result.Index(i), result.Index(j))
+		})
+	}
+	if length > 0 {
+		start, end := offset, offset+length
+		if start > result.Len() {
+			start = result.Len()
+		}
+		if end > result.Len() {
+			end = result.Len()
+		}
+		result = result.Slice(start, end)
+	}
+	dest.Elem().Set(result)
+	return nil
+}
+
+//orderLess 根据Order生成结果的比较函数，排序字段需要能对应到结果中的字段
+func orderLess(elemType reflect.Type, orders []Order) (func(a, b reflect.Value) bool, error) {
+	if len(orders) == 0 {
+		return nil, nil
+	}
+	structType := elemType
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	type sortKey struct {
+		index []int
+		desc  bool
+	}
+	var keys []sortKey
+	for _, order := range orders {
+		if structType.Kind() != reflect.Struct || structType == timeType {
+			keys = append(keys, sortKey{desc: order.Desc})
+			continue
+		}
+		s, err := schemaOfType(structType)
+		if err != nil {
+			return nil, err
+		}
+		name := strings.Trim(order.Field, "` ")
+		if i := strings.LastIndex(name, "."); i > -1 {
+			name = strings.Trim(name[i+1:], "`")
+		}
+		var index []int
+		for _, col := range s.columns {
+			if col.name == name {
+				index = col.index
+			}
+		}
+		if index == nil {
+			return nil, fmt.Errorf("[Model Select]: The order field `%s` is not found in %s", order.Field, structType)
+		}
+		keys = append(keys, sortKey{index: index, desc: order.Desc})
+	}
+	return func(a, b reflect.Value) bool {
+		a, b = reflect.Indirect(a), reflect.Indirect(b)
+		for _, key := range keys {
+			va, vb := a, b
+			if key.index != nil {
+				va, vb = a.FieldByIndex(key.index), b.FieldByIndex(key.index)
+			}
+			c := compareValues(va.Interface(), vb.Interface())
+			if c == 0 {
+				continue
+			}
+			if key.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	}, nil
+}
+
+//compareValues 比较两个查询结果中的值，NULL最小
+func compareValues(a, b interface{}) int {
+	a, b = driverValue(a), driverValue(b)
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return -1
+	case b == nil:
+		return 1
+	}
+	switch va := a.(type) {
+	case time.Time:
+		if vb, ok := b.(time.Time); ok {
+			return va.Compare(vb)
+		}
+	case []byte:
+		if vb, ok := b.([]byte); ok {
+			return bytes.Compare(va, vb)
+		}
+	case string:
+		if vb, ok := b.(string); ok {
+			return strings.Compare(va, vb)
+		}
+	case bool:
+		if vb, ok := b.(bool); ok && va != vb {
+			if va {
+				return 1
+			}
+			return -1
+		}
+		return 0
+	}
+	fa, okA := toFloat(a)
+	fb, okB := toFloat(b)
+	if okA && okB {
+		switch {
+		case fa < fb:
+			return -1
+		case fa > fb:
+			return 1
+		}
+		return 0
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func driverValue(value interface{}) interface{} {
+	if valuer, ok := value.(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil
+		}
+		return v
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if !rv.IsValid() {
+		return nil
+	}
+	return rv.Interface()
+}
+
+func toFloat(value interface{}) (float64, bool) {
+	rv := reflect.ValueOf(value)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
+
+//shardAggregate 在全部分片上并发聚合后合并，COUNT/SUM按精确值求和，MAX/MIN取最值
+///各分片的去重结果相加会重复计数，跨分片时只支持对分片键 COUNT(DISTINCT)
+func (m *Model) shardAggregate(function string, field string, dest interface{}) error {
+	targets, err := m.shardTargets("[Model " + function + "]")
+	if err != nil {
+		return m.record("", err)
+	}
+	if len(targets) == 1 {
+		targets[0].aggregate(function, field, dest)
+		return m.recordShards(targets)
+	}
+	if function != "COUNT" && function != "SUM" && function != "MAX" && function != "MIN" {
+		return m.record("", fmt.Errorf("[Model %s]: The aggregate is not supported across shards", function))
+	}
+	if !m.shardDistinct(function, field) {
+		return m.record("", fmt.Errorf("[Model %s]: The DISTINCT aggregate is only supported on the shard key across shards", function))
+	}
+	if function == "COUNT" || function == "SUM" {
+		parts := make([]sql.NullString, len(targets))
+		m.scatter(targets, func(i int, target *Model) {
+			target.aggregate(function, field, &parts[i])
+		})
+		for _, target := range targets {
+			if target.Error() != nil {
+				return m.recordShards(targets)
+			}
+		}
+		sum, err := sumParts(parts)
+		if err == nil {
+			err = scanSum(sum, dest)
+		}
+		if err != nil {
+			return m.recordShards(targets, fmt.Errorf("[Model %s]: %w", function, err))
+		}
+		return m.recordShards(targets)
+	}
+	destType := reflect.TypeOf(dest).Elem()
+	parts := make([]reflect.Value, len(targets))
+	m.scatter(targets, func(i int, target *Model) {
+		part := reflect.New(destType)
+		target.aggregate(function, field, part.Interface())
+		parts[i] = part.Elem()
+	})
+	if err = m.recordShards(targets); err != nil {
+		return err
+	}
+	var result interface{}
+	for _, part := range parts {
+		value := driverValue(part.Interface())
+		if value == nil {
+			continue
+		}
+		switch {
+		case result == nil:
+			result = value
+		case function == "MAX" && compareValues(value, result) > 0:
+			result = value
+		case function == "MIN" && compareValues(value, result) < 0:
+			result = value
+		}
+	}
+	if result == nil {
+		return nil
+	}
+	switch d := dest.(type) {
+	case *int64:
+		f, _ := toFloat(result)
+		*d = int64(f)
+	case *sql.NullFloat64:
+		f, _ := toFloat(result)
+		*d = sql.NullFloat64{Float64: f, Valid: true}
+	default:
+		return d.(sql.Scanner).Scan(result)
+	}
+	return nil
+}
+
+//shardDistinct 去重聚合只能在分片键上 COUNT，否则各分片的结果相加会重复计数
+func (m *Model) shardDistinct(function string, field string) bool {
+	fields := strings.Fields(field)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], "DISTINCT") {
+		return true
+	}
+	if function != "COUNT" {
+		return function == "MAX" || function == "MIN"
+	}
+	return len(fields) == 2 && strings.Trim(fields[1], "`") == m.Sharding.Key
+}
+
+//sumParts 按精确值合并各分片的COUNT/SUM，避免超过2^53的整数及DECIMAL经过float64丢失精度
+func sumParts(parts []sql.NullString) (*big.Rat, error) {
+	var sum *big.Rat
+	for _, part := range parts {
+		if !part.Valid {
+			continue
+		}
+		value, ok := new(big.Rat).SetString(part.String)
+		if !ok {
+			return nil, fmt.Errorf("The value `%s` is not a number", part.String)
+		}
+		if sum == nil {
+			sum = value
+		} else {
+			sum.Add(sum, value)
+		}
+	}
+	return sum, nil
+}
+
+func scanSum(sum *big.Rat, dest interface{}) error {
+	if sum == nil {
+		return nil
+	}
+	switch d := dest.(type) {
+	case *int64:
+		if !sum.IsInt() || !sum.Num().IsInt64() {
+			return fmt.Errorf("The sum %s overflows int64", sum.RatString())
+		}
+		*d = sum.Num().Int64()
+	case *sql.NullFloat64:
+		f, _ := sum.Float64()
+		*d = sql.NullFloat64{Float64: f, Valid: true}
+	default:
+		if sum.IsInt() {
+			return d.(sql.Scanner).Scan(sum.Num().String())
+		}
+		return d.(sql.Scanner).Scan(sum.FloatString(10))
+	}
+	return nil
+}
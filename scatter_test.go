@@ -0,0 +1,126 @@
+package mysqlgo
+
+import (
+	"database/sql"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+)
+
+func TestScatter(t *testing.T) {
+	orderModel := &Model{
+		TableName: "b_order",
+		Sharding: &Sharding{
+			Key: "user_id",
+			Shards: []Shard{
+				{TableName: "b_order_0"},
+				{TableName: "b_order_1"},
+			},
+			FanOut: true,
+		},
+	}
+
+	t.Run("scatter limit", func(t *testing.T) {
+		var orders []struct{ ID int64 }
+		query := orderModel.Where("status = ?", 1).Order(Order{Field: "id"}).Limit(Limit{Offset: 20, Length: 10})
+		query.Select(&orders)
+		for _, table := range []string{"b_order_0", "b_order_1"} {
+			if !strings.Contains(query.LastSQL(), "FROM "+table+" ") {
+				t.Fatalf("scatter fail : %s", query.LastSQL())
+			}
+		}
+		if strings.Count(query.LastSQL(), "Limit 30") != 2 {
+			t.Fatalf("scatter limit fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("merge order", func(t *testing.T) {
+		type order struct {
+			ID     int64          `db:"id"`
+			Amount float64        `db:"amount"`
+			Note   sql.NullString `db:"note"`
+		}
+		rows := []order{
+			{ID: 1, Amount: 10},
+			{ID: 2, Amount: 30},
+			{ID: 3, Amount: 10, Note: sql.NullString{String: "b", Valid: true}},
+			{ID: 4, Amount: 20},
+		}
+		less, err := orderLess(reflect.TypeOf(order{}), []Order{{Field: "o.`amount`", Desc: true}, {Field: "note"}})
+		if err != nil {
+			t.Fatalf("order less fail : %v", err)
+		}
+		values := reflect.ValueOf(rows)
+		sort.SliceStable(rows, func(i, j int) bool {
+			return less(values.Index(i), values.Index(j))
+		})
+		var ids []int64
+		for _, row := range rows {
+			ids = append(ids, row.ID)
+		}
+		if !reflect.DeepEqual(ids, []int64{2, 4, 1, 3}) {
+			t.Fatalf("merge order fail : %v", ids)
+		}
+		if _, err = orderLess(reflect.TypeOf(order{}), []Order{{Field: "created_at"}}); err == nil {
+			t.Fatalf("unknown order field fail")
+		}
+	})
+
+	t.Run("compare values", func(t *testing.T) {
+		testCases := []struct {
+			a, b   interface{}
+			result int
+		}{
+			{a: 1, b: int64(2), result: -1},
+			{a: uint8(3), b: 2.5, result: 1},
+			{a: "b", b: "a", result: 1},
+			{a: []byte("a"), b: []byte("a"), result: 0},
+			{a: nil, b: 0, result: -1},
+			{a: sql.NullInt64{}, b: sql.NullInt64{Int64: -1, Valid: true}, result: -1},
+		}
+		for _, testCase := range testCases {
+			if result := compareValues(testCase.a, testCase.b); result != testCase.result {
+				t.Fatalf("compare values fail case : %v, result : %d", testCase, result)
+			}
+		}
+	})
+
+	t.Run("merge sum", func(t *testing.T) {
+		parts := []sql.NullString{
+			{String: "9007199254740993", Valid: true},
+			{String: "1", Valid: true},
+			{},
+		}
+		sum, err := sumParts(parts)
+		if err != nil {
+			t.Fatalf("merge sum fail : %v", err)
+		}
+		var count int64
+		if err = scanSum(sum, &count); err != nil || count != 9007199254740994 {
+			t.Fatalf("merge count fail : %d, %v", count, err)
+		}
+		sum, _ = sumParts([]sql.NullString{{String: "0.10", Valid: true}, {String: "0.20", Valid: true}})
+		var total sql.NullString
+		if err = scanSum(sum, &total); err != nil || total.String != "0.3000000000" {
+			t.Fatalf("merge decimal fail : %v, %v", total, err)
+		}
+		sum, _ = sumParts([]sql.NullString{{String: "9223372036854775807", Valid: true}, {String: "1", Valid: true}})
+		if err = scanSum(sum, &count); err == nil {
+			t.Fatalf("merge overflow should fail")
+		}
+	})
+
+	t.Run("distinct aggregate", func(t *testing.T) {
+		if _, err := orderModel.Count("DISTINCT product_id"); err == nil {
+			t.Fatalf("count distinct across shards should fail")
+		}
+		if _, err := orderModel.Sum("DISTINCT amount"); err == nil {
+			t.Fatalf("sum distinct across shards should fail")
+		}
+		query := orderModel.FetchSQL()
+		if _, err := query.Count("DISTINCT user_id"); err != nil {
+			t.Fatalf("count distinct shard key fail : %v", err)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+package mysqlgo
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+//Scope 查询范围，接收并返回查询构建器，用于复用常用的查询条件
+type Scope func(m *Model) *Model
+
+//Scope 应用在Model.Scopes中注册的查询范围
+func (m *Model) Scope(names ...string) *Model {
+	q := m.clone()
+	for _, name := range names {
+		scope, ok := m.Scopes[name]
+		if !ok {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model Scope]: The scope `%s` is not registered", name))
+			continue
+		}
+		q = scope(q)
+	}
+	return q
+}
+
+//Apply 应用查询范围函数
+func (m *Model) Apply(scopes ...Scope) *Model {
+	q := m.clone()
+	for _, scope := range scopes {
+		q = scope(q)
+	}
+	return q
+}
+
+//WithoutScope 本次查询不应用指定的全局查询范围，不传入名称时不应用全部全局查询范围
+func (m *Model) WithoutScope(names ...string) *Model {
+	q := m.clone()
+	if len(names) == 0 {
+		for name := range m.GlobalScopes {
+			names = append(names, name)
+		}
+	}
+	q.options.withoutScopes = append(q.options.withoutScopes, names...)
+	return q
+}
+
+//applyGlobalScopes 按名称顺序应用未被移除的全局查询范围
+///每个全局查询范围的条件单独加括号后与已有的条件AND，已有条件中的OR不会绕过全局查询范围
+func (m *Model) applyGlobalScopes() *Model {
+	if len(m.GlobalScopes) == 0 {
+		return m
+	}
+	excluded := make(map[string]bool, 0)
+	if m.options != nil {
+		for _, name := range m.options.withoutScopes {
+			excluded[name] = true
+		}
+	}
+	var names []string
+	for name := range m.GlobalScopes {
+		if !excluded[name] {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	q := m.clone()
+	//全局查询范围只应用一次
+	q.GlobalScopes = nil
+	for _, name := range names {
+		where, args := q.options.where, q.options.whereArgs
+		q.options.where, q.options.whereArgs = "", nil
+		q = m.GlobalScopes[name](q)
+		scoped, scopedArgs := q.options.where, q.options.whereArgs
+		q.options.where, q.options.whereArgs = where, args
+		if scoped != "" {
+			q.options.and(fmt.Sprintf("(%s)", strings.TrimSpace(scoped)), scopedArgs...)
+		}
+	}
+	return q
+}
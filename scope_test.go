@@ -0,0 +1,57 @@
+package mysqlgo
+
+import (
+	"testing"
+)
+
+func TestScope(t *testing.T) {
+	t.Run("named and global scope", func(t *testing.T) {
+		userModel := &Model{
+			TableName: "b_user",
+			Scopes: map[string]Scope{
+				"enabled": func(m *Model) *Model {
+					return m.Where("status = ?", 1)
+				},
+			},
+			GlobalScopes: map[string]Scope{
+				"tenant": func(m *Model) *Model {
+					return m.Where("tenant_id = ?", 7)
+				},
+			},
+		}
+		vip := func(m *Model) *Model {
+			return m.Where("level > ?", 3)
+		}
+		testCases := []struct {
+			in    *Model
+			where string
+			args  int
+		}{
+			{in: userModel.Scope("enabled"), where: " (status = ?) and (tenant_id = ?) ", args: 2},
+			{in: userModel.Apply(vip), where: " (level > ?) and (tenant_id = ?) ", args: 2},
+			{in: userModel.Where("a = ? OR b = ?", 1, 2), where: " (a = ? OR b = ?) and (tenant_id = ?) ", args: 3},
+			{in: userModel, where: "(tenant_id = ?)", args: 1},
+			{in: userModel.Where("author = ? OR editor = ?", 1, 2).Scope("enabled").WithoutScope("tenant"), where: " (author = ? OR editor = ?) and (status = ?) ", args: 3},
+			{in: userModel.Where("author = ? OR editor = ?", 1, 2).Where("level > ?", 3).Scope("enabled").WithoutScope("tenant"),
+				where: " ((author = ? OR editor = ?) and (level > ?)) and (status = ?) ", args: 4},
+			{in: userModel.Scope("enabled").WithoutScope("tenant"), where: "status = ?", args: 1},
+			{in: userModel.WithoutScope(), where: "", args: 0},
+		}
+		for _, testCase := range testCases {
+			options, errs := testCase.in.prepare()
+			if len(errs) > 0 {
+				t.Fatalf("scope fail : %v", errs)
+			}
+			if options.where != testCase.where || len(options.whereArgs) != testCase.args {
+				t.Fatalf("scope fail case : %v, where : %s, args : %v", testCase.where, options.where, options.whereArgs)
+			}
+		}
+	})
+
+	t.Run("unregistered scope", func(t *testing.T) {
+		userModel := &Model{TableName: "b_user"}
+		if _, errs := userModel.Scope("enabled").prepare(); len(errs) == 0 {
+			t.Fatalf("unregistered scope should fail")
+		}
+	})
+}
@@ -0,0 +1,364 @@
+package mysqlgo
+
+import (
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+//Sharding 分片配置，逻辑上的Model按分片键映射到多个数据库别名或数据表
+type Sharding struct {
+	Key    string    //分片键
+	Shards []Shard   //物理分片
+	Func   ShardFunc //分片函数，默认为ModShard
+	FanOut bool      //条件中不包含分片键时，true为在全部分片上执行，false为返回错误
+	//Concurrency 在全部分片上执行查询时的最大并发数，默认为8
+	Concurrency int
+}
+
+//Shard 物理分片
+type Shard struct {
+	DBAlias   string //数据库别名，为空时使用Model的DBAlias
+	TableName string //数据表名，为空时使用Model的TableName
+}
+
+//ShardFunc 分片函数，返回分片键对应的分片序号
+type ShardFunc func(key interface{}, shards int) (int, error)
+
+//ModShard 按整数分片键取模
+func ModShard(key interface{}, shards int) (int, error) {
+	v, err := shardInt(key)
+	if err != nil {
+		return -1, err
+	}
+	if v < 0 {
+		v = -v
+	}
+	return int(v % int64(shards)), nil
+}
+
+//RangeShard 按范围分片，bounds为每个分片的上界(不包含)，超过最后一个上界的属于最后一个分片
+///如 RangeShard(1000000, 2000000) 将 [0,1000000) [1000000,2000000) [2000000,∞) 分为三个分片
+func RangeShard(bounds ...int64) ShardFunc {
+	return func(key interface{}, shards int) (int, error) {
+		v, err := shardInt(key)
+		if err != nil {
+			return -1, err
+		}
+		index := sort.Search(len(bounds), func(i int) bool {
+			return v < bounds[i]
+		})
+		if index >= shards {
+			return -1, fmt.Errorf("[Model Shard]: The key %d is out of range", v)
+		}
+		return index, nil
+	}
+}
+
+//HashShard 一致性哈希分片，replicas为每个分片的虚拟节点数量
+func HashShard(replicas int) ShardFunc {
+	if replicas <= 0 {
+		replicas = 64
+	}
+	var rings sync.Map
+	return func(key interface{}, shards int) (int, error) {
+		cached, ok := rings.Load(shards)
+		if !ok {
+			cached, _ = rings.LoadOrStore(shards, newHashRing(shards, replicas))
+		}
+		return cached.(*hashRing).get(keyString(key)), nil
+	}
+}
+
+type hashRing struct {
+	hashes []uint32
+	shards map[uint32]int
+}
+
+func newHashRing(shards int, replicas int) *hashRing {
+	ring := &hashRing{shards: make(map[uint32]int, shards*replicas)}
+	for i := 0; i < shards; i++ {
+		for r := 0; r < replicas; r++ {
+			hash := crc32.ChecksumIEEE([]byte(fmt.Sprintf("%d#%d", i, r)))
+			if _, ok := ring.shards[hash]; ok {
+				continue
+			}
+			ring.shards[hash] = i
+			ring.hashes = append(ring.hashes, hash)
+		}
+	}
+	sort.Slice(ring.hashes, func(i, j int) bool {
+		return ring.hashes[i] < ring.hashes[j]
+	})
+	return ring
+}
+
+func (ring *hashRing) get(key string) int {
+	hash := crc32.ChecksumIEEE([]byte(key))
+	index := sort.Search(len(ring.hashes), func(i int) bool {
+		return ring.hashes[i] >= hash
+	})
+	if index == len(ring.hashes) {
+		index = 0
+	}
+	return ring.shards[ring.hashes[index]]
+}
+
+func shardInt(key interface{}) (int64, error) {
+	rv := reflect.ValueOf(key)
+	for rv.Kind() == reflect.Ptr && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(rv.Uint()), nil
+	}
+	v, err := strconv.ParseInt(keyString(key), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("[Model Shard]: The key `%v` is not an integer", key)
+	}
+	return v, nil
+}
+
+var (
+	shardPatterns sync.Map
+	shardOr       = regexp.MustCompile(`(?i)\bOR\b|\|\|`)
+	shardNot      = regexp.MustCompile(`(?i)\bNOT\s*$`)
+	shardGroup    = regexp.MustCompile(`(?i)(^|\bAND|\bWHERE|\()\s*$`)
+	shardSelect   = regexp.MustCompile(`(?i)\bSELECT\b`)
+)
+
+//shardPattern 分片键的匹配规则，按分片键缓存
+func shardPattern(key string) *regexp.Regexp {
+	if pattern, ok := shardPatterns.Load(key); ok {
+		return pattern.(*regexp.Regexp)
+	}
+	pattern := regexp.MustCompile(`(^|[\s(])(` + "`?" + `(\w+)` + "`?" + `\.)?` + "`?" + regexp.QuoteMeta(key) + "`?" + `\s*=\s*\?`)
+	actual, _ := shardPatterns.LoadOrStore(key, pattern)
+	return actual.(*regexp.Regexp)
+}
+
+//shardWhere 从条件中查找 key = ? 形式的分片键，返回对应的参数
+///分片键只能不带表名或以主表(tables)限定，条件中包含OR、分片键在NOT或子查询中时无法确定分片
+func shardWhere(where string, args []interface{}, key string, tables ...string) (interface{}, bool) {
+	if where == "" || shardOr.MatchString(where) {
+		return nil, false
+	}
+	positions := placeholderPositions(where)
+	for _, loc := range shardPattern(key).FindAllStringSubmatchIndex(where, -1) {
+		if loc[6] >= 0 && !shardTable(where[loc[6]:loc[7]], tables) {
+			continue
+		}
+		if !shardTopLevel(where, loc[3]) {
+			continue
+		}
+		//引号或注释中的 key = ? 不是条件，参数按引号和注释之外的占位符定位
+		index := sort.SearchInts(positions, loc[1]-1)
+		if index >= len(positions) || positions[index] != loc[1]-1 {
+			continue
+		}
+		if index >= len(args) {
+			return nil, false
+		}
+		return args[index], true
+	}
+	return nil, false
+}
+
+func shardTable(qualifier string, tables []string) bool {
+	for _, table := range tables {
+		if strings.Trim(table, "`") == qualifier {
+			return true
+		}
+	}
+	return false
+}
+
+//shardTopLevel 分片键不在NOT之后，所在的括号只用于AND条件分组，不是函数、NOT、IN或子查询
+func shardTopLevel(where string, start int) bool {
+	if shardNot.MatchString(where[:start]) {
+		return false
+	}
+	depth := 0
+	for i := start - 1; i >= 0; i-- {
+		switch where[i] {
+		case ')':
+			depth++
+		case '(':
+			if depth > 0 {
+				depth--
+				continue
+			}
+			before := where[:i]
+			if shardNot.MatchString(before) || !shardGroup.MatchString(before) || shardSelect.MatchString(where[i:start]) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func (s *Sharding) shardFunc() ShardFunc {
+	if s.Func == nil {
+		return ModShard
+	}
+	return s.Func
+}
+
+//onShard 返回在指定分片上执行的Model
+func (m *Model) onShard(index int) *Model {
+	q := m.clone()
+	shard := m.Sharding.Shards[index]
+	if shard.DBAlias != "" {
+		q.DBAlias = shard.DBAlias
+	}
+	if shard.TableName != "" {
+		q.TableName = shard.TableName
+	}
+	q.Sharding = nil
+	return q
+}
+
+//shardByKey 返回分片键对应的Model
+func (m *Model) shardByKey(key interface{}) (*Model, error) {
+	if len(m.Sharding.Shards) == 0 {
+		return nil, errors.New("[Model Shard]: The Shards is null")
+	}
+	index, err := m.Sharding.shardFunc()(key, len(m.Sharding.Shards))
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 || index >= len(m.Sharding.Shards) {
+		return nil, fmt.Errorf("[Model Shard]: The shard %d is out of range", index)
+	}
+	return m.onShard(index), nil
+}
+
+//shardTargets 按条件中的分片键路由，不包含分片键时根据FanOut返回全部分片或错误
+func (m *Model) shardTargets(name string) ([]*Model, error) {
+	var where string
+	var args []interface{}
+	if m.options != nil {
+		where, args = m.options.where, m.options.whereArgs
+	}
+	tables := []string{m.TableName, m.getTableName()}
+	if m.options != nil && len(m.options.table) > 0 {
+		tables = []string{m.options.table[0].Name, m.options.table[0].Alias}
+	}
+	if key, ok := shardWhere(where, args, m.Sharding.Key, tables...); ok {
+		target, err := m.shardByKey(key)
+		if err != nil {
+			return nil, err
+		}
+		return []*Model{target}, nil
+	}
+	if !m.Sharding.FanOut {
+		return nil, fmt.Errorf("%s: %s : %w", name, m.Sharding.Key, ErrShardKeyRequired)
+	}
+	targets := make([]*Model, 0, len(m.Sharding.Shards))
+	for i := range m.Sharding.Shards {
+		targets = append(targets, m.onShard(i))
+	}
+	return targets, nil
+}
+
+//recordShards 汇总各个分片的SQL和错误，errs为合并结果时产生的错误
+func (m *Model) recordShards(targets []*Model, errs ...error) error {
+	var sqls []string
+	var args []interface{}
+	for _, target := range targets {
+		sqls = append(sqls, target.LastSQL())
+		args = append(args, target.LastArgs()...)
+		target.stateMu.Lock()
+		errs = append(errs, target.err...)
+		target.stateMu.Unlock()
+	}
+	return m.recordArgs(strings.Join(sqls, ";\n"), args, errs...)
+}
+
+func (m *Model) shardAdd(datas []Data) (int64, error) {
+	for _, data := range datas {
+		if data.Field != m.Sharding.Key {
+			continue
+		}
+		target, err := m.shardByKey(data.Value)
+		if err != nil {
+			return -1, m.record("", err)
+		}
+		id, _ := target.Add(datas...)
+		return id, m.recordShards([]*Model{target})
+	}
+	return -1, m.record("", fmt.Errorf("[Model Add]: %s : %w", m.Sharding.Key, ErrShardKeyRequired))
+}
+
+//shardAddAll 按分片键分组写入，每个分片在各自的事务中写入
+func (m *Model) shardAddAll(datas [][]Data) error {
+	var order []int
+	groups := make(map[int][][]Data, 0)
+	for _, data := range datas {
+		index := -1
+		for _, d := range data {
+			if d.Field != m.Sharding.Key {
+				continue
+			}
+			i, err := m.Sharding.shardFunc()(d.Value, len(m.Sharding.Shards))
+			if err != nil {
+				return m.record("", err)
+			}
+			index = i
+		}
+		if index < 0 || index >= len(m.Sharding.Shards) {
+			return m.record("", fmt.Errorf("[Model AddAll]: %s : %w", m.Sharding.Key, ErrShardKeyRequired))
+		}
+		if _, ok := groups[index]; !ok {
+			order = append(order, index)
+		}
+		groups[index] = append(groups[index], data)
+	}
+	var targets []*Model
+	for _, index := range order {
+		target := m.onShard(index)
+		targets = append(targets, target)
+		if target.AddAll(groups[index]...) != nil {
+			break
+		}
+	}
+	return m.recordShards(targets)
+}
+
+//shardExec 在路由到的分片上执行修改，返回影响的总行数
+///verify不为nil时，在全部分片执行完成后检查影响的总行数
+func (m *Model) shardExec(name string, exec func(target *Model) (int64, error), verify func(total int64) error) (int64, error) {
+	targets, err := m.shardTargets(name)
+	if err != nil {
+		return -1, m.record("", err)
+	}
+	var total int64
+	executed := targets
+	failed := false
+	for i, target := range targets {
+		rows, err := exec(target)
+		if err != nil {
+			executed, failed = targets[:i+1], true
+			break
+		}
+		total += rows
+	}
+	if !failed && verify != nil {
+		if err = verify(total); err != nil {
+			return 0, m.recordShards(executed, err)
+		}
+	}
+	if err = m.recordShards(executed); err != nil {
+		return -1, err
+	}
+	return total, nil
+}
@@ -0,0 +1,96 @@
+package mysqlgo
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestShard(t *testing.T) {
+	orderModel := &Model{
+		TableName: "b_order",
+		Sharding: &Sharding{
+			Key: "user_id",
+			Shards: []Shard{
+				{TableName: "b_order_0"},
+				{TableName: "b_order_1"},
+			},
+		},
+	}
+
+	t.Run("shard func", func(t *testing.T) {
+		testCases := []struct {
+			fn    ShardFunc
+			key   interface{}
+			index int
+		}{
+			{fn: ModShard, key: 4, index: 1},
+			{fn: ModShard, key: "6", index: 0},
+			{fn: RangeShard(100, 200), key: int64(99), index: 0},
+			{fn: RangeShard(100, 200), key: uint(150), index: 1},
+			{fn: RangeShard(100, 200), key: 1000, index: 2},
+		}
+		for _, testCase := range testCases {
+			index, err := testCase.fn(testCase.key, 3)
+			if err != nil || index != testCase.index {
+				t.Fatalf("shard func fail case : %v, index : %d, err : %v", testCase, index, err)
+			}
+		}
+		hash := HashShard(16)
+		first, _ := hash("user-1", 4)
+		second, _ := hash("user-1", 4)
+		if first != second || first < 0 || first >= 4 {
+			t.Fatalf("hash shard fail : %d, %d", first, second)
+		}
+	})
+
+	t.Run("shard where", func(t *testing.T) {
+		testCases := []struct {
+			where string
+			args  []interface{}
+			key   interface{}
+			ok    bool
+		}{
+			{where: "user_id = ?", args: []interface{}{5}, key: 5, ok: true},
+			{where: "status = ? and b_order.`user_id`=?", args: []interface{}{1, 6}, key: 6, ok: true},
+			{where: " (status = ?) and (user_id = ?) ", args: []interface{}{1, 6}, key: 6, ok: true},
+			{where: "o.user_id = ? and user_id = ?", args: []interface{}{1, 6}, key: 6, ok: true},
+			{where: "status = ? or user_id = ?", args: []interface{}{1, 6}, ok: false},
+			{where: "status = ?\nOR\tuser_id = ?", args: []interface{}{1, 6}, ok: false},
+			{where: "parent_user_id = ?", args: []interface{}{1}, ok: false},
+			{where: "note = 'why?' AND user_id = ? AND status = ?", args: []interface{}{5, 1}, key: 5, ok: true},
+			{where: "note = 'user_id = ?' AND status = ?", args: []interface{}{1}, ok: false},
+			{where: "/* ? */ user_id = ?", args: []interface{}{5}, key: 5, ok: true},
+			{where: "o.user_id = ?", args: []interface{}{1}, ok: false},
+			{where: "NOT (user_id = ?)", args: []interface{}{1}, ok: false},
+			{where: "NOT user_id = ?", args: []interface{}{1}, ok: false},
+			{where: "id IN (SELECT id FROM b_log WHERE user_id = ?)", args: []interface{}{1}, ok: false},
+			{where: "EXISTS (SELECT 1 FROM b_log WHERE (user_id = ?))", args: []interface{}{1}, ok: false},
+		}
+		for _, testCase := range testCases {
+			key, ok := shardWhere(testCase.where, testCase.args, "user_id", "b_order")
+			if ok != testCase.ok || key != testCase.key {
+				t.Fatalf("shard where fail case : %v, key : %v", testCase, key)
+			}
+		}
+	})
+
+	t.Run("shard route", func(t *testing.T) {
+		var orders []struct{ ID int64 }
+		query := orderModel.Where("user_id = ?", 3)
+		query.Select(&orders)
+		if !strings.Contains(query.LastSQL(), "FROM b_order_1 ") {
+			t.Fatalf("shard route fail : %s", query.LastSQL())
+		}
+		if err := orderModel.Where("status = ?", 1).Select(&orders); !errors.Is(err, ErrShardKeyRequired) {
+			t.Fatalf("shard key required fail : %v", err)
+		}
+		if _, err := orderModel.Where("user_id = ?", 3).Update(Data{Field: "user_id", Value: 4}); err == nil {
+			t.Fatalf("update shard key should fail")
+		}
+		orderModel.Add(Data{Field: "user_id", Value: 4})
+		if !strings.Contains(orderModel.LastSQL(), "INSERT INTO b_order_0") {
+			t.Fatalf("shard add fail : %s", orderModel.LastSQL())
+		}
+	})
+}
@@ -0,0 +1,137 @@
+package mysqlgo
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+//软删除标记字段的类型
+const (
+	SoftDeleteUnix     = iota //unix时间戳，0为未删除
+	SoftDeleteDatetime        //DATETIME，NULL为未删除
+	SoftDeleteFlag            //0为未删除，1为已删除
+)
+
+//软删除查询范围
+const (
+	trashedExclude = iota //默认不包含已删除的数据
+	trashedWith           //包含已删除的数据
+	trashedOnly           //只查询已删除的数据
+)
+
+//SoftDelete 软删除配置
+type SoftDelete struct {
+	Field string //标记字段，如deleted_at、is_deleted
+	Type  int    //标记字段的类型，默认为SoftDeleteUnix
+}
+
+//WithTrashed 查询结果包含已软删除的数据
+func (m *Model) WithTrashed() *Model {
+	q := m.clone()
+	q.options.trashed = trashedWith
+	return q
+}
+
+//OnlyTrashed 只查询已软删除的数据
+func (m *Model) OnlyTrashed() *Model {
+	q := m.clone()
+	q.options.trashed = trashedOnly
+	return q
+}
+
+//Restore 恢复已软删除的数据
+func (m *Model) Restore() (int64, error) {
+	if m.Sharding != nil {
+		return m.shardExec("[Model Restore]", (*Model).Restore, nil)
+	}
+	if m.SoftDelete == nil {
+		return -1, m.record("", errors.New("[Model Restore]: The SoftDelete is not configured"))
+	}
+	var value interface{} = 0
+	if m.SoftDelete.Type == SoftDeleteDatetime {
+		value = nil
+	}
+	options, errs := m.prepare()
+	options.trashed = trashedOnly
+	return m.markDeleted(options, errs, "[Model Restore]", value)
+}
+
+//ForceDelete 物理删除数据，忽略软删除配置
+func (m *Model) ForceDelete() (int64, error) {
+	if m.Sharding != nil {
+		return m.shardExec("[Model Delete]", (*Model).ForceDelete, nil)
+	}
+	return m.destroy()
+}
+
+func (m *Model) softDelete() (int64, error) {
+	var value interface{}
+	switch m.SoftDelete.Type {
+	case SoftDeleteDatetime:
+		value = time.Now()
+	case SoftDeleteFlag:
+		value = 1
+	default:
+		value = time.Now().Unix()
+	}
+	options, errs := m.prepare()
+	options.trashed = trashedExclude
+	return m.markDeleted(options, errs, "[Model Delete]", value)
+}
+
+//markDeleted 以UPDATE的方式修改软删除标记，配置了Timestamps时同时更新修改时间
+func (m *Model) markDeleted(options *option, errs []error, name string, value interface{}) (int64, error) {
+	if len(errs) > 0 {
+		return -1, m.record("", errs...)
+	}
+	if options.where == "" && len(options.whereArgs) == 0 {
+		return -1, m.record("", fmt.Errorf("%s: %w", name, ErrEmptyCondition))
+	}
+	m.scopeSoftDelete(options)
+	if err := m.scopeTenant(options); err != nil {
+		return -1, m.record("", err)
+	}
+	datas := m.fillTimestamps(options, []Data{{Field: m.SoftDelete.Field, Value: value}}, false)
+	sql, args, err := m.parseUpdateSQL(updateSQL, options.where, options.whereArgs, datas...)
+	if err != nil {
+		return -1, m.record(sql, err)
+	}
+	sql += m.parseComment(options)
+	db, err := m.getExecutor(options)
+	if err != nil {
+		return -1, m.recordArgs(sql, args, classifyError(err))
+	}
+	result, err := db.ExecContext(options.context(), sql, args...)
+	if err != nil {
+		return -1, m.recordArgs(sql, args, classifyError(err))
+	}
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return -1, m.recordArgs(sql, args, classifyError(err))
+	}
+	return rows, m.recordArgs(sql, args)
+}
+
+//scopeSoftDelete 根据查询范围追加软删除条件
+func (m *Model) scopeSoftDelete(options *option) {
+	if m.SoftDelete == nil || m.SoftDelete.Field == "" || options.trashed == trashedWith {
+		return
+	}
+	field := m.qualify(options, m.SoftDelete.Field)
+	var where string
+	if m.SoftDelete.Type == SoftDeleteDatetime {
+		if options.trashed == trashedOnly {
+			where = fmt.Sprintf("%s IS NOT NULL", field)
+		} else {
+			where = fmt.Sprintf("%s IS NULL", field)
+		}
+	} else {
+		if options.trashed == trashedOnly {
+			where = fmt.Sprintf("%s <> 0", field)
+		} else {
+			where = fmt.Sprintf("%s = 0", field)
+		}
+	}
+	options.and(where)
+}
@@ -0,0 +1,123 @@
+package mysqlgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSoftDelete(t *testing.T) {
+	t.Run("soft delete scope", func(t *testing.T) {
+		userModel := &Model{
+			TableName: "b_user",
+			SoftDelete: &SoftDelete{
+				Field: "delete_time",
+			},
+		}
+		datetimeModel := &Model{
+			TableName: "b_user",
+			SoftDelete: &SoftDelete{
+				Field: "deleted_at",
+				Type:  SoftDeleteDatetime,
+			},
+		}
+		timestampModel := &Model{
+			TableName:  "b_user",
+			SoftDelete: &SoftDelete{Field: "delete_time"},
+			Timestamps: &Timestamps{Created: "create_time", Updated: "update_time"},
+		}
+		user := []struct {
+			Account string
+		}{}
+		testCases := []struct {
+			in   func() *Model
+			want string
+		}{
+			{
+				in: func() *Model {
+					query := userModel.Where("status = ?", 1)
+					query.Select(&user)
+					return query
+				},
+				want: "WHERE  (status = ?) and delete_time = 0",
+			},
+			{
+				in: func() *Model {
+					query := userModel.WithTrashed()
+					query.Select(&user)
+					return query
+				},
+				want: "FROM b_user ",
+			},
+			{
+				in: func() *Model {
+					query := userModel.OnlyTrashed()
+					query.Count()
+					return query
+				},
+				want: "SELECT COUNT(*) FROM b_user WHERE delete_time <> 0",
+			},
+			{
+				in: func() *Model {
+					query := userModel.Where("id = ?", 1)
+					query.Delete()
+					return query
+				},
+				want: "UPDATE b_user SET  `delete_time` = ?  WHERE  (id = ?) and delete_time = 0",
+			},
+			{
+				in: func() *Model {
+					query := datetimeModel.Where("id = ?", 1)
+					query.Restore()
+					return query
+				},
+				want: "UPDATE b_user SET  `deleted_at` = ?  WHERE  (id = ?) and deleted_at IS NOT NULL",
+			},
+			{
+				in: func() *Model {
+					query := datetimeModel.Where("id = ?", 1)
+					query.ForceDelete()
+					return query
+				},
+				want: "DELETE FROM b_user WHERE id = ?",
+			},
+			{
+				in: func() *Model {
+					query := userModel.Where("id = ?", 1)
+					query.Update(Data{Field: "status", Value: 2})
+					return query
+				},
+				want: "UPDATE b_user SET  `status` = ?  WHERE  (id = ?) and delete_time = 0",
+			},
+			{
+				in: func() *Model {
+					query := userModel.WithTrashed().Where("id = ?", 1)
+					query.Update(Data{Field: "status", Value: 2})
+					return query
+				},
+				want: "UPDATE b_user SET  `status` = ?  WHERE id = ?",
+			},
+			{
+				in: func() *Model {
+					query := timestampModel.Where("id = ?", 1)
+					query.Delete()
+					return query
+				},
+				want: "`delete_time` = ? , `update_time` = ?  WHERE  (id = ?) and delete_time = 0",
+			},
+			{
+				in: func() *Model {
+					query := timestampModel.Where("id = ?", 1)
+					query.Restore()
+					return query
+				},
+				want: "`delete_time` = ? , `update_time` = ?  WHERE  (id = ?) and delete_time <> 0",
+			},
+		}
+		for _, testCase := range testCases {
+			query := testCase.in()
+			if !strings.Contains(query.LastSQL(), testCase.want) {
+				t.Fatalf("soft delete fail case : %s, sql : %s", testCase.want, query.LastSQL())
+			}
+		}
+	})
+}
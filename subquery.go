@@ -0,0 +1,169 @@
+package mysqlgo
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+//FieldSub 在查询字段中使用标量子查询
+///如 FieldSub(orderModel.FieldRaw("COUNT(*)").Where("user_id = b_user.id"), "order_num")
+func (m *Model) FieldSub(sub *Model, alias string) *Model {
+	q := m.clone()
+	if sub == nil || !identifierPart.MatchString(alias) {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model FieldSub]: %s : %w", alias, ErrInvalidIdentifier))
+		return q
+	}
+	q.options.subFields = append(q.options.subFields, Table{Sub: sub, Alias: alias})
+	return q
+}
+
+//subquery 获取子查询的Model，参数为 *Model 或 *TypedModel
+func subquery(arg interface{}) (*Model, bool) {
+	switch v := arg.(type) {
+	case *Model:
+		return v, v != nil
+	case interface{ Model() *Model }:
+		return v.Model(), v.Model() != nil
+	}
+	return nil, false
+}
+
+//compile 生成子查询的SQL及参数
+///子查询未指定上下文和事务时，使用外层查询的上下文和事务
+func (o *option) compile(sub *Model) (string, []interface{}, error) {
+	q := sub.clone()
+	if q.options.ctx == nil {
+		q.options.ctx = o.ctx
+	}
+	if q.options.tx == nil {
+		q.options.tx = o.tx
+	}
+	q.options.cteNames = append(q.options.cteNames, o.cteNames...)
+	q.options.nested = true
+	if q.Sharding != nil {
+		return "", nil, errors.New("[Model Subquery]: The sharding model can't be used as a subquery")
+	}
+	options, errs := q.prepare()
+	if len(errs) > 0 {
+		return "", nil, fmt.Errorf("[Model Subquery]: %w", errors.Join(errs...))
+	}
+	q.scopeSoftDelete(options)
+	if err := q.scopeTenant(options); err != nil {
+		return "", nil, err
+	}
+	return q.parseSelectSQL(selectSQL, options), options.args(), nil
+}
+
+//compileSubqueries 将公用表表达式、字段、数据表、联表、条件中的子查询及合并的查询生成SQL，参数按在语句中的顺序合并
+func (o *option) compileSubqueries() error {
+	if err := o.compileCTE(); err != nil {
+		return err
+	}
+	for _, field := range o.subFields {
+		sql, args, err := o.compile(field.Sub)
+		if err != nil {
+			return err
+		}
+		if o.field == "" {
+			o.field = "*"
+		}
+		o.field = fmt.Sprintf("%s,(%s) AS `%s`", o.field, sql, strings.Trim(field.Alias, "`"))
+		o.fieldArgs = append(o.fieldArgs, args...)
+	}
+	for i, table := range o.table {
+		if table.Sub == nil {
+			if o.isCTE(table.Name) {
+				o.table[i].Name = "`" + table.Name + "`"
+			}
+			continue
+		}
+		sql, args, err := o.compile(table.Sub)
+		if err != nil {
+			return err
+		}
+		o.table[i].sql = sql
+		o.tableArgs = append(o.tableArgs, args...)
+	}
+	for i, join := range o.join {
+		if join.Sub != nil {
+			sql, args, err := o.compile(join.Sub)
+			if err != nil {
+				return err
+			}
+			o.join[i].sql = sql
+			o.joinArgs = append(o.joinArgs, args...)
+		} else if o.isCTE(join.table) {
+			o.join[i].table = "`" + join.table + "`"
+		}
+		o.joinArgs = append(o.joinArgs, join.args...)
+	}
+	where, args, err := o.expand(o.where, o.whereArgs)
+	if err != nil {
+		return err
+	}
+	o.where, o.whereArgs = where, args
+	for i, part := range o.unions {
+		if part.query == nil {
+			continue
+		}
+		sql, args, err := o.compile(part.query)
+		if err != nil {
+			return err
+		}
+		o.unions[i].sql = sql
+		o.unionArgs = append(o.unionArgs, args...)
+	}
+	return nil
+}
+
+//expand 将条件参数中的子查询替换为SQL，如 Where("id IN ?", subModel) 为 id IN (SELECT ...)
+func (o *option) expand(where string, args []interface{}) (string, []interface{}, error) {
+	hasSub := false
+	for _, arg := range args {
+		if _, ok := subquery(arg); ok {
+			hasSub = true
+			break
+		}
+	}
+	if !hasSub {
+		return where, args, nil
+	}
+	var b strings.Builder
+	var merged []interface{}
+	index := 0
+	for _, r := range where {
+		if r != '?' || index >= len(args) {
+			b.WriteRune(r)
+			continue
+		}
+		arg := args[index]
+		index++
+		sub, ok := subquery(arg)
+		if !ok {
+			b.WriteRune(r)
+			merged = append(merged, arg)
+			continue
+		}
+		sql, subArgs, err := o.compile(sub)
+		if err != nil {
+			return "", nil, err
+		}
+		b.WriteString("(" + sql + ")")
+		merged = append(merged, subArgs...)
+	}
+	merged = append(merged, args[index:]...)
+	return b.String(), merged, nil
+}
+
+//args 按语句中的顺序获取全部参数
+func (o *option) args() []interface{} {
+	var args []interface{}
+	args = append(args, o.cteArgs...)
+	args = append(args, o.fieldArgs...)
+	args = append(args, o.tableArgs...)
+	args = append(args, o.joinArgs...)
+	args = append(args, o.whereArgs...)
+	args = append(args, o.unionArgs...)
+	return args
+}
@@ -0,0 +1,61 @@
+package mysqlgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSubquery(t *testing.T) {
+	userModel := &Model{
+		TableName: "b_user",
+	}
+	orderModel := &Model{
+		TableName:  "b_order",
+		SoftDelete: &SoftDelete{Field: "deleted_at", Type: SoftDeleteDatetime},
+	}
+
+	t.Run("where subquery", func(t *testing.T) {
+		sub := orderModel.Field("user_id").Where("amount > ?", 100)
+		options, errs := userModel.Where("status = ? AND id IN ? AND role = ?", 1, sub, "admin").prepare()
+		if len(errs) > 0 {
+			t.Fatalf("where subquery fail : %v", errs)
+		}
+		want := "status = ? AND id IN (SELECT `user_id` FROM b_order WHERE  (amount > ?) and deleted_at IS NULL   ) AND role = ?"
+		if options.where != want {
+			t.Fatalf("where subquery sql fail : %s", options.where)
+		}
+		if !reflect.DeepEqual(options.args(), []interface{}{1, 100, "admin"}) {
+			t.Fatalf("where subquery args fail : %v", options.args())
+		}
+	})
+
+	t.Run("args order", func(t *testing.T) {
+		count := orderModel.FieldRaw("COUNT(*)").Where("user_id = u.id AND status = ?", "paid")
+		latest := orderModel.Field("user_id").Where("created_at > ?", "2024-01-01").Group("user_id")
+		recent := userModel.Field("id").Where("level = ?", 3)
+		query := userModel.Field("u.id").FieldSub(count, "order_num").
+			Table(Table{Sub: recent, Alias: "u"}).
+			Join(Join{Sub: latest, Alias: "o", Statement: "o.user_id = u.id"}).
+			Where("u.id > ?", 10)
+		query.Select(&[]struct{ ID int }{})
+		for _, part := range []string{
+			"SELECT `u`.`id`,(SELECT COUNT(*) FROM b_order",
+			") AS `order_num` FROM  (SELECT `id` FROM b_user WHERE level = ? ",
+			"INNER JOIN (SELECT `user_id` FROM b_order",
+			") o ON o.user_id = u.id",
+			"WHERE u.id > ?",
+		} {
+			if !strings.Contains(query.LastSQL(), part) {
+				t.Fatalf("args order sql fail : %s, sql : %s", part, query.LastSQL())
+			}
+		}
+		options, _ := query.prepare()
+		if !reflect.DeepEqual(options.args(), []interface{}{"paid", 3, "2024-01-01", 10}) {
+			t.Fatalf("args order fail : %v", options.args())
+		}
+		if err := userModel.Table(Table{Sub: recent}).Select(&[]struct{ ID int }{}); err == nil {
+			t.Fatalf("subquery alias fail")
+		}
+	})
+}
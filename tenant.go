@@ -0,0 +1,87 @@
+package mysqlgo
+
+import (
+	"context"
+	"fmt"
+)
+
+type tenantKey struct{}
+
+//WithTenant 在上下文中设置租户ID
+func WithTenant(ctx context.Context, tenantID interface{}) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenantID)
+}
+
+//TenantFromContext 获取上下文中的租户ID
+func TenantFromContext(ctx context.Context) (interface{}, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tenantID := ctx.Value(tenantKey{})
+	return tenantID, tenantID != nil
+}
+
+//WithoutTenant 本次操作不限制租户，用于跨租户的管理操作
+func (m *Model) WithoutTenant() *Model {
+	q := m.clone()
+	q.options.withoutTenant = true
+	return q
+}
+
+//tenant 获取本次操作的租户ID，声明了TenantColumn的表必须在上下文中指定租户
+func (m *Model) tenant(options *option) (interface{}, bool, error) {
+	if m.TenantColumn == "" || options.withoutTenant {
+		return nil, false, nil
+	}
+	tenantID, ok := TenantFromContext(options.ctx)
+	if !ok {
+		return nil, false, fmt.Errorf("[Model Tenant]: %s : %w", m.getTableName(), ErrTenantRequired)
+	}
+	return tenantID, true, nil
+}
+
+//scopeTenant 追加租户条件
+func (m *Model) scopeTenant(options *option) error {
+	tenantID, ok, err := m.tenant(options)
+	if !ok {
+		return err
+	}
+	options.and(fmt.Sprintf("%s = ?", m.qualify(options, m.TenantColumn)), tenantID)
+	return nil
+}
+
+//fillTenant 写入租户字段，已传入的租户与上下文中的租户不一致时返回错误
+func (m *Model) fillTenant(options *option, datas []Data) ([]Data, error) {
+	tenantID, ok, err := m.tenant(options)
+	if !ok {
+		return datas, err
+	}
+	if err := m.matchTenant(tenantID, datas); err != nil {
+		return nil, err
+	}
+	result := make([]Data, 0, len(datas)+1)
+	for _, data := range datas {
+		if data.Field != m.TenantColumn {
+			result = append(result, data)
+		}
+	}
+	return append(result, Data{Field: m.TenantColumn, Value: tenantID}), nil
+}
+
+//checkTenant 更新时不能将数据修改到其他租户
+func (m *Model) checkTenant(options *option, datas []Data) error {
+	tenantID, ok, err := m.tenant(options)
+	if !ok {
+		return err
+	}
+	return m.matchTenant(tenantID, datas)
+}
+
+func (m *Model) matchTenant(tenantID interface{}, datas []Data) error {
+	for _, data := range datas {
+		if data.Field == m.TenantColumn && keyString(data.Value) != keyString(tenantID) {
+			return fmt.Errorf("[Model Tenant]: The tenant `%v` does not match the context tenant `%v`", data.Value, tenantID)
+		}
+	}
+	return nil
+}
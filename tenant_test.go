@@ -0,0 +1,61 @@
+package mysqlgo
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestTenant(t *testing.T) {
+	orderModel := &Model{
+		TableName:    "b_order",
+		TenantColumn: "tenant_id",
+	}
+	ctx := WithTenant(context.Background(), 7)
+
+	t.Run("tenant condition", func(t *testing.T) {
+		var orders []struct{ ID int64 }
+		query := orderModel.WithContext(ctx).Where("status = ?", 1)
+		query.Select(&orders)
+		if !strings.Contains(query.LastSQL(), "WHERE  (status = ?) and tenant_id = ?") {
+			t.Fatalf("tenant condition fail : %s", query.LastSQL())
+		}
+		query = orderModel.WithContext(ctx).Where("id = ?", 1)
+		query.Delete()
+		if !strings.Contains(query.LastSQL(), "DELETE FROM b_order WHERE  (id = ?) and tenant_id = ?") {
+			t.Fatalf("tenant condition fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("tenant required", func(t *testing.T) {
+		var orders []struct{ ID int64 }
+		if err := orderModel.Where("status = ?", 1).Select(&orders); !errors.Is(err, ErrTenantRequired) {
+			t.Fatalf("tenant required fail : %v", err)
+		}
+		if _, err := orderModel.Add(Data{Field: "amount", Value: 1}); !errors.Is(err, ErrTenantRequired) {
+			t.Fatalf("tenant required fail : %v", err)
+		}
+	})
+
+	t.Run("fill tenant", func(t *testing.T) {
+		options, _ := orderModel.WithContext(ctx).prepare()
+		datas, err := orderModel.fillTenant(options, []Data{{Field: "amount", Value: 1}})
+		if err != nil || len(datas) != 2 || datas[1].Field != "tenant_id" || datas[1].Value != 7 {
+			t.Fatalf("fill tenant fail : %v, %v", datas, err)
+		}
+		if _, err = orderModel.fillTenant(options, []Data{{Field: "tenant_id", Value: 8}}); err == nil {
+			t.Fatalf("tenant mismatch should fail")
+		}
+	})
+
+	t.Run("update tenant", func(t *testing.T) {
+		query := orderModel.WithContext(ctx).FetchSQL().Where("id = ?", 1)
+		if _, err := query.Update(Data{Field: "tenant_id", Value: 8}); err == nil {
+			t.Fatalf("update tenant mismatch should fail : %s", query.LastSQL())
+		}
+		if _, err := query.Update(Data{Field: "tenant_id", Value: 7}, Data{Field: "amount", Value: 2}); err != nil {
+			t.Fatalf("update tenant fail : %v", err)
+		}
+	})
+}
@@ -0,0 +1,86 @@
+package mysqlgo
+
+import (
+	"reflect"
+	"time"
+)
+
+//时间字段的格式
+const (
+	TimestampUnix     = iota //unix时间戳(秒)
+	TimestampMilli           //unix时间戳(毫秒)
+	TimestampDatetime        //DATETIME
+)
+
+//Timestamps 自动维护创建时间和更新时间
+type Timestamps struct {
+	Created string //创建时间字段，如create_time，为空时不维护
+	Updated string //更新时间字段，如update_time，为空时不维护
+	Type    int    //时间字段的格式，默认为TimestampUnix
+}
+
+//WithoutTimestamps 本次操作不自动写入时间字段
+func (m *Model) WithoutTimestamps() *Model {
+	q := m.clone()
+	q.options.noTimestamps = true
+	return q
+}
+
+func (t *Timestamps) now() interface{} {
+	now := time.Now()
+	switch t.Type {
+	case TimestampMilli:
+		return now.UnixNano() / int64(time.Millisecond)
+	case TimestampDatetime:
+		return now
+	default:
+		return now.Unix()
+	}
+}
+
+//fillTimestamps 写入时间字段，已传入非零值的字段不会被覆盖
+///create为true时同时写入创建时间和更新时间，否则只写入更新时间
+func (m *Model) fillTimestamps(options *option, datas []Data, create bool) []Data {
+	if m.Timestamps == nil || options.noTimestamps {
+		return datas
+	}
+	now := m.Timestamps.now()
+	var fields []string
+	if create && m.Timestamps.Created != "" {
+		fields = append(fields, m.Timestamps.Created)
+	}
+	if m.Timestamps.Updated != "" {
+		fields = append(fields, m.Timestamps.Updated)
+	}
+	result := make([]Data, 0, len(datas)+len(fields))
+	for _, data := range datas {
+		//更新时不覆盖创建时间
+		if !create && data.Field == m.Timestamps.Created && isZeroValue(data.Value) {
+			continue
+		}
+		result = append(result, data)
+	}
+	for _, field := range fields {
+		found := false
+		for i, data := range result {
+			if data.Field != field {
+				continue
+			}
+			found = true
+			if isZeroValue(data.Value) {
+				result[i].Value = now
+			}
+		}
+		if !found {
+			result = append(result, Data{Field: field, Value: now})
+		}
+	}
+	return result
+}
+
+func isZeroValue(value interface{}) bool {
+	if value == nil {
+		return true
+	}
+	return reflect.ValueOf(value).IsZero()
+}
@@ -0,0 +1,66 @@
+package mysqlgo
+
+import (
+	"testing"
+)
+
+func TestFillTimestamps(t *testing.T) {
+	t.Run("fill timestamps", func(t *testing.T) {
+		userModel := &Model{
+			TableName: "b_user",
+			Timestamps: &Timestamps{
+				Created: "create_time",
+				Updated: "update_time",
+			},
+		}
+		testCases := []struct {
+			model  *Model
+			in     []Data
+			create bool
+			out    []string
+		}{
+			{
+				model:  userModel,
+				in:     []Data{{Field: "account", Value: "test1"}},
+				create: true,
+				out:    []string{"account", "create_time", "update_time"},
+			},
+			{
+				model:  userModel,
+				in:     []Data{{Field: "account", Value: "test1"}, {Field: "create_time", Value: int64(0)}},
+				create: false,
+				out:    []string{"account", "update_time"},
+			},
+			{
+				model:  userModel.WithoutTimestamps(),
+				in:     []Data{{Field: "account", Value: "test1"}},
+				create: true,
+				out:    []string{"account"},
+			},
+		}
+		for _, testCase := range testCases {
+			options, _ := testCase.model.prepare()
+			datas := testCase.model.fillTimestamps(options, testCase.in, testCase.create)
+			if len(datas) != len(testCase.out) {
+				t.Fatalf("fill timestamps fail case : %v, datas : %v", testCase.out, datas)
+			}
+			for i, data := range datas {
+				if data.Field != testCase.out[i] || isZeroValue(data.Value) {
+					t.Fatalf("fill timestamps fail case : %v, datas : %v", testCase.out, datas)
+				}
+			}
+		}
+	})
+
+	t.Run("keep given value", func(t *testing.T) {
+		userModel := &Model{
+			TableName:  "b_user",
+			Timestamps: &Timestamps{Created: "create_time"},
+		}
+		options, _ := userModel.prepare()
+		datas := userModel.fillTimestamps(options, []Data{{Field: "create_time", Value: int64(1)}}, true)
+		if len(datas) != 1 || datas[0].Value != int64(1) {
+			t.Fatalf("keep given value fail : %v", datas)
+		}
+	})
+}
@@ -0,0 +1,119 @@
+package mysqlgo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/jmoiron/sqlx"
+)
+
+//Tx 数据库事务
+type Tx struct {
+	alias string
+	tx    *sqlx.Tx
+}
+
+type executor interface {
+	GetContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	SelectContext(ctx context.Context, dest interface{}, query string, args ...interface{}) error
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+//Begin 开启指定别名数据库的事务
+///如果不传入别名则默认为"default"
+func Begin(alias ...string) (*Tx, error) {
+	name := "default"
+	if len(alias) > 0 {
+		name = alias[0]
+	}
+	db, err := lookupDB(name)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := db.Beginx()
+	if err != nil {
+		return nil, classifyError(err)
+	}
+	return &Tx{alias: name, tx: tx}, nil
+}
+
+//Commit 提交事务
+func (tx *Tx) Commit() error {
+	return classifyError(tx.tx.Commit())
+}
+
+//Rollback 回滚事务
+func (tx *Tx) Rollback() error {
+	return classifyError(tx.tx.Rollback())
+}
+
+//Transaction 在事务中执行fn，fn返回错误或panic时回滚，否则提交
+func Transaction(fn func(tx *Tx) error, alias ...string) (err error) {
+	tx, err := Begin(alias...)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if p := recover(); p != nil {
+			tx.Rollback()
+			panic(p)
+		}
+	}()
+	if err = fn(tx); err != nil {
+		if rbErr := tx.Rollback(); rbErr != nil {
+			return fmt.Errorf("%w; rollback: %v", err, rbErr)
+		}
+		return err
+	}
+	return tx.Commit()
+}
+
+//Tx 在指定的事务中执行
+func (m *Model) Tx(tx *Tx) *Model {
+	q := m.clone()
+	q.options.tx = tx
+	return q
+}
+
+//getExecutor 获取执行语句的连接，在事务中时使用事务，预览模式下不执行语句
+func (m *Model) getExecutor(options *option) (executor, error) {
+	if options.dryRun() {
+		return dryExecutor{}, nil
+	}
+	if options.tx != nil {
+		return options.tx.tx, nil
+	}
+	alias, err := m.resolveAlias(options)
+	if err != nil {
+		return nil, err
+	}
+	//路由到租户数据库或要求别名已配置时不能回退到"default"
+	var db *sqlx.DB
+	if alias != m.getDBAlias() || options.strictAlias {
+		db, err = lookupDB(alias)
+	} else {
+		db, err = getDB(alias)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return db, nil
+}
+
+//transaction 在事务中执行fn，已经在事务中时直接使用当前事务
+func (m *Model) transaction(options *option, fn func(exec executor) error) error {
+	if options.dryRun() {
+		return fn(dryExecutor{})
+	}
+	if options.tx != nil {
+		return fn(options.tx.tx)
+	}
+	alias, err := m.resolveAlias(options)
+	if err != nil {
+		return err
+	}
+	return Transaction(func(tx *Tx) error {
+		return fn(tx.tx)
+	}, alias)
+}
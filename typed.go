@@ -0,0 +1,435 @@
+package mysqlgo
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+)
+
+//TypedModel 泛型表模型
+///在Model的基础上，根据T的结构体标签推导表名和字段
+///标签格式: `db:"字段名,选项"`，选项pk表示主键，auto表示自增
+type TypedModel[T any] struct {
+	model *Model
+}
+
+//TableNamer 自定义表名
+type TableNamer interface {
+	TableName() string
+}
+
+type column struct {
+	name  string
+	index []int
+	pk    bool
+	auto  bool
+}
+
+type schema struct {
+	table   string
+	columns []column
+}
+
+var schemas sync.Map
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+)
+
+//NewTypedModel 创建泛型表模型
+///model为nil或未指定TableName时，表名由T推导
+func NewTypedModel[T any](model *Model) *TypedModel[T] {
+	s, err := schemaOf[T]()
+	if model == nil {
+		model = &Model{}
+	}
+	base := model.clone()
+	if err != nil {
+		base.options.err = append(base.options.err, err)
+	} else if base.TableName == "" {
+		base.TableName = s.table
+	}
+	return &TypedModel[T]{model: base}
+}
+
+//Model 返回底层的表模型
+func (t *TypedModel[T]) Model() *Model {
+	return t.model
+}
+
+func (t *TypedModel[T]) with(m *Model) *TypedModel[T] {
+	return &TypedModel[T]{model: m}
+}
+
+//Table 指定当前的数据表
+func (t *TypedModel[T]) Table(tables ...Table) *TypedModel[T] {
+	return t.with(t.model.Table(tables...))
+}
+
+//Field 指定字段名
+func (t *TypedModel[T]) Field(fields ...string) *TypedModel[T] {
+	return t.with(t.model.Field(fields...))
+}
+
+//FieldRaw 追加原样写入的查询字段
+func (t *TypedModel[T]) FieldRaw(fields ...Raw) *TypedModel[T] {
+	return t.with(t.model.FieldRaw(fields...))
+}
+
+//FieldWindow 追加窗口函数到查询字段
+func (t *TypedModel[T]) FieldWindow(funcs ...*WindowFunc) *TypedModel[T] {
+	return t.with(t.model.FieldWindow(funcs...))
+}
+
+//Window 定义命名窗口
+func (t *TypedModel[T]) Window(name string, window Window) *TypedModel[T] {
+	return t.with(t.model.Window(name, window))
+}
+
+//Where 指定查询条件
+func (t *TypedModel[T]) Where(where string, args ...interface{}) *TypedModel[T] {
+	return t.with(t.model.Where(where, args...))
+}
+
+//Order 对操作的结果排序
+func (t *TypedModel[T]) Order(orders ...Order) *TypedModel[T] {
+	return t.with(t.model.Order(orders...))
+}
+
+//OrderRaw 原样写入的排序表达式
+func (t *TypedModel[T]) OrderRaw(order Raw) *TypedModel[T] {
+	return t.with(t.model.OrderRaw(order))
+}
+
+//Filter 按字段过滤
+func (t *TypedModel[T]) Filter(field string, op string, value interface{}) *TypedModel[T] {
+	return t.with(t.model.Filter(field, op, value))
+}
+
+//Limit 指定查询和操作的数量
+func (t *TypedModel[T]) Limit(limit Limit) *TypedModel[T] {
+	return t.with(t.model.Limit(limit))
+}
+
+//Page 指定分页
+func (t *TypedModel[T]) Page(page int, listRows int) *TypedModel[T] {
+	return t.with(t.model.Page(page, listRows))
+}
+
+//Group 一个或多个列对结果集进行分组
+func (t *TypedModel[T]) Group(fields ...string) *TypedModel[T] {
+	return t.with(t.model.Group(fields...))
+}
+
+//GroupRaw 原样写入的分组表达式
+func (t *TypedModel[T]) GroupRaw(groups ...Raw) *TypedModel[T] {
+	return t.with(t.model.GroupRaw(groups...))
+}
+
+//Having 配合group方法完成从分组的结果中筛选
+func (t *TypedModel[T]) Having(having string) *TypedModel[T] {
+	return t.with(t.model.Having(having))
+}
+
+//Distinct 用于返回唯一不同的值
+func (t *TypedModel[T]) Distinct(distinct bool) *TypedModel[T] {
+	return t.with(t.model.Distinct(distinct))
+}
+
+//Join 用于根据两个或多个表中的列之间的关系，从这些表中查询数据
+func (t *TypedModel[T]) Join(join Join) *TypedModel[T] {
+	return t.with(t.model.Join(join))
+}
+
+//UnionQuery 合并其他查询的结果集并去除重复的行
+func (t *TypedModel[T]) UnionQuery(queries ...*Model) *TypedModel[T] {
+	return t.with(t.model.UnionQuery(queries...))
+}
+
+//UnionAll 合并其他查询的结果集并保留重复的行
+func (t *TypedModel[T]) UnionAll(queries ...*Model) *TypedModel[T] {
+	return t.with(t.model.UnionAll(queries...))
+}
+
+//ForceIndex 强制主表使用指定的索引
+func (t *TypedModel[T]) ForceIndex(indexes ...string) *TypedModel[T] {
+	return t.with(t.model.ForceIndex(indexes...))
+}
+
+//UseIndex 建议主表使用指定的索引
+func (t *TypedModel[T]) UseIndex(indexes ...string) *TypedModel[T] {
+	return t.with(t.model.UseIndex(indexes...))
+}
+
+//IgnoreIndex 主表忽略指定的索引
+func (t *TypedModel[T]) IgnoreIndex(indexes ...string) *TypedModel[T] {
+	return t.with(t.model.IgnoreIndex(indexes...))
+}
+
+//Hint 添加优化器提示
+func (t *TypedModel[T]) Hint(hints ...string) *TypedModel[T] {
+	return t.with(t.model.Hint(hints...))
+}
+
+//Comment 为语句添加注释
+func (t *TypedModel[T]) Comment(comment string) *TypedModel[T] {
+	return t.with(t.model.Comment(comment))
+}
+
+//FetchSQL 预览模式，只生成SQL不执行
+func (t *TypedModel[T]) FetchSQL() *TypedModel[T] {
+	return t.with(t.model.FetchSQL())
+}
+
+//ToSQL 生成查询语句及参数，不执行
+func (t *TypedModel[T]) ToSQL() (string, []interface{}, error) {
+	return t.model.ToSQL()
+}
+
+//With 预加载关联数据
+func (t *TypedModel[T]) With(relations ...string) *TypedModel[T] {
+	return t.with(t.model.With(relations...))
+}
+
+//WithCTE 定义公用表表达式
+func (t *TypedModel[T]) WithCTE(name string, query *Model) *TypedModel[T] {
+	return t.with(t.model.WithCTE(name, query))
+}
+
+//WithRecursive 定义递归的公用表表达式
+func (t *TypedModel[T]) WithRecursive(name string, anchor *Model, recursive *Model) *TypedModel[T] {
+	return t.with(t.model.WithRecursive(name, anchor, recursive))
+}
+
+//LastSQL 最后执行生成的SQL语句
+func (t *TypedModel[T]) LastSQL() string {
+	return t.model.LastSQL()
+}
+
+//LastArgs 最后执行的语句的参数
+func (t *TypedModel[T]) LastArgs() []interface{} {
+	return t.model.LastArgs()
+}
+
+//DebugSQL 将最后执行的语句与参数合并，仅用于调试
+func (t *TypedModel[T]) DebugSQL() string {
+	return t.model.DebugSQL()
+}
+
+//Error 执行过程中出现的所有错误
+func (t *TypedModel[T]) Error() error {
+	return t.model.Error()
+}
+
+//First 查找一条数据
+func (t *TypedModel[T]) First() (T, error) {
+	var dest T
+	m := t.selectColumns()
+	err := m.Find(&dest)
+	t.adopt(m)
+	if err != nil {
+		return dest, err
+	}
+	return dest, nil
+}
+
+//All 查询全部数据
+func (t *TypedModel[T]) All() ([]T, error) {
+	var dest []T
+	m := t.selectColumns()
+	err := m.Select(&dest)
+	t.adopt(m)
+	if err != nil {
+		return nil, err
+	}
+	return dest, nil
+}
+
+//Insert 新增数据，返回自增ID
+///自增主键为零值时不写入
+func (t *TypedModel[T]) Insert(value T) (int64, error) {
+	s, rv, err := t.valueOf("[TypedModel Insert]", value)
+	if err != nil {
+		return -1, t.model.record("", err)
+	}
+	var datas []Data
+	for _, col := range s.columns {
+		field := rv.FieldByIndex(col.index)
+		if col.auto && field.IsZero() {
+			continue
+		}
+		datas = append(datas, Data{Field: col.name, Value: field.Interface()})
+	}
+	return t.model.Add(datas...)
+}
+
+//Update 更新数据，返回影响的行数
+///未指定Where条件时，以主键作为条件
+func (t *TypedModel[T]) Update(value T) (int64, error) {
+	s, rv, err := t.valueOf("[TypedModel Update]", value)
+	if err != nil {
+		return -1, t.model.record("", err)
+	}
+	var datas []Data
+	var pks []column
+	for _, col := range s.columns {
+		if col.pk {
+			pks = append(pks, col)
+			continue
+		}
+		datas = append(datas, Data{Field: col.name, Value: rv.FieldByIndex(col.index).Interface()})
+	}
+	m := t.model
+	if m.options == nil || m.options.where == "" {
+		if len(pks) == 0 {
+			return -1, t.model.record("", fmt.Errorf("[TypedModel Update]: %s has no primary key and no condition", s.table))
+		}
+		for _, pk := range pks {
+			m = m.Where(fmt.Sprintf("%s = ?", pk.name), rv.FieldByIndex(pk.index).Interface())
+		}
+	}
+	id, err := m.Update(datas...)
+	t.adopt(m)
+	return id, err
+}
+
+//valueOf 获取T的结构及字段值，T为nil指针时返回错误
+func (t *TypedModel[T]) valueOf(name string, value T) (*schema, reflect.Value, error) {
+	s, err := schemaOf[T]()
+	if err != nil {
+		return nil, reflect.Value{}, err
+	}
+	rv := reflect.ValueOf(value)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil, reflect.Value{}, fmt.Errorf("%s: The value is nil", name)
+		}
+		rv = rv.Elem()
+	}
+	return s, rv, nil
+}
+
+//adopt 将在副本上执行的SQL、参数及错误记录到t.model，使LastSQL、Error等可用
+func (t *TypedModel[T]) adopt(m *Model) {
+	if m == t.model {
+		return
+	}
+	m.stateMu.Lock()
+	sql, args, errs := m.sql, m.args, append([]error(nil), m.err...)
+	m.stateMu.Unlock()
+	t.model.stateMu.Lock()
+	defer t.model.stateMu.Unlock()
+	t.model.sql, t.model.args, t.model.err = sql, args, errs
+}
+
+func (t *TypedModel[T]) selectColumns() *Model {
+	if t.model.options != nil && t.model.options.field != "" {
+		return t.model
+	}
+	s, err := schemaOf[T]()
+	if err != nil {
+		return t.model
+	}
+	var fields []string
+	for _, col := range s.columns {
+		fields = append(fields, col.name)
+	}
+	return t.model.Field(fields...)
+}
+
+func schemaOf[T any]() (*schema, error) {
+	var zero T
+	return schemaOfType(reflect.TypeOf(&zero).Elem())
+}
+
+func schemaOfType(typ reflect.Type) (*schema, error) {
+	if cached, ok := schemas.Load(typ); ok {
+		return cached.(*schema), nil
+	}
+	structType := typ
+	for structType.Kind() == reflect.Ptr {
+		structType = structType.Elem()
+	}
+	if structType.Kind() != reflect.Struct {
+		return nil, errors.New("[TypedModel schema]: T must be a struct type")
+	}
+	s := &schema{
+		table:   toSnakeCase(structType.Name()),
+		columns: parseColumns(structType, nil),
+	}
+	if namer, ok := reflect.New(structType).Interface().(TableNamer); ok {
+		s.table = namer.TableName()
+	}
+	schemas.Store(typ, s)
+	return s, nil
+}
+
+func parseColumns(typ reflect.Type, index []int) []column {
+	var columns []column
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("db")
+		if tag == "-" {
+			continue
+		}
+		idx := append(append([]int{}, index...), i)
+		if field.Anonymous && tag == "" && field.Type.Kind() == reflect.Struct {
+			columns = append(columns, parseColumns(field.Type, idx)...)
+			continue
+		}
+		if field.PkgPath != "" || (tag == "" && isRelationType(field.Type)) {
+			continue
+		}
+		parts := strings.Split(tag, ",")
+		col := column{name: parts[0], index: idx}
+		if col.name == "" {
+			col.name = strings.ToLower(field.Name)
+		}
+		for _, opt := range parts[1:] {
+			switch strings.TrimSpace(opt) {
+			case "pk":
+				col.pk = true
+			case "auto":
+				col.auto = true
+			}
+		}
+		columns = append(columns, col)
+	}
+	return columns
+}
+
+//isRelationType 结构体或结构体切片类型的字段用于存放关联数据，不作为数据表字段
+func isRelationType(typ reflect.Type) bool {
+	if typ.Kind() == reflect.Slice {
+		typ = typ.Elem()
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || typ == timeType {
+		return false
+	}
+	return !reflect.PointerTo(typ).Implements(scannerType)
+}
+
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteByte('_')
+			}
+			b.WriteRune(unicode.ToLower(r))
+		} else {
+			b.WriteRune(r)
+		}
+	}
+	return b.String()
+}
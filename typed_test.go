@@ -0,0 +1,147 @@
+package mysqlgo
+
+import (
+	"testing"
+)
+
+type userProfile struct {
+	ID       int64  `db:"id,pk,auto"`
+	Account  string `db:"account"`
+	Password string
+	Ignore   string `db:"-"`
+}
+
+type namedUser struct {
+	ID int64 `db:"id,pk"`
+}
+
+func (namedUser) TableName() string {
+	return "b_user"
+}
+
+func TestTypedModelSchema(t *testing.T) {
+	t.Run("derive table and columns", func(t *testing.T) {
+		s, err := schemaOf[userProfile]()
+		if err != nil {
+			t.Fatalf("schema fail : %v", err)
+		}
+		if s.table != "user_profile" {
+			t.Fatalf("table name fail : %s", s.table)
+		}
+		var names []string
+		for _, col := range s.columns {
+			names = append(names, col.name)
+		}
+		if len(names) != 3 || names[0] != "id" || names[1] != "account" || names[2] != "password" {
+			t.Fatalf("columns fail : %v", names)
+		}
+		if !s.columns[0].pk || !s.columns[0].auto {
+			t.Fatalf("primary key fail : %+v", s.columns[0])
+		}
+	})
+
+	t.Run("table namer", func(t *testing.T) {
+		userModel := NewTypedModel[namedUser](nil)
+		if userModel.Model().TableName != "b_user" {
+			t.Fatalf("table name fail : %s", userModel.Model().TableName)
+		}
+		userModel = NewTypedModel[namedUser](&Model{TableName: "b_admin", DBAlias: "slave"})
+		if userModel.Model().TableName != "b_admin" || userModel.Model().DBAlias != "slave" {
+			t.Fatalf("model override fail : %+v", userModel.Model())
+		}
+	})
+
+	t.Run("snake case", func(t *testing.T) {
+		testCases := []struct {
+			in  string
+			out string
+		}{
+			{in: "User", out: "user"},
+			{in: "UserProfile", out: "user_profile"},
+			{in: "HTTPLog", out: "http_log"},
+		}
+		for _, testCase := range testCases {
+			if out := toSnakeCase(testCase.in); out != testCase.out {
+				t.Fatalf("snake case fail case : %v, out : %s", testCase, out)
+			}
+		}
+	})
+}
+
+type logEntry struct {
+	Message string `db:"message"`
+}
+
+func TestTypedModelSQL(t *testing.T) {
+	profileModel := NewTypedModel[userProfile](&Model{DBAlias: "typed"}).FetchSQL()
+
+	testCases := []struct {
+		name string
+		exec func() error
+		sql  string
+		args int
+	}{
+		{
+			name: "first",
+			exec: func() error {
+				_, err := profileModel.First()
+				return err
+			},
+			sql: "SELECT `id`,`account`,`password` FROM user_profile Limit 1  ",
+		},
+		{
+			name: "all",
+			exec: func() error {
+				_, err := profileModel.All()
+				return err
+			},
+			sql: "SELECT `id`,`account`,`password` FROM user_profile ",
+		},
+		{
+			name: "insert skip zero auto pk",
+			exec: func() error {
+				_, err := profileModel.Insert(userProfile{Account: "tom", Password: "123"})
+				return err
+			},
+			sql:  "INSERT INTO user_profile(`account`,`password`) VALUE(?,?)",
+			args: 2,
+		},
+		{
+			name: "update by pk",
+			exec: func() error {
+				_, err := profileModel.Update(userProfile{ID: 3, Account: "tom", Password: "123"})
+				return err
+			},
+			sql:  "UPDATE user_profile SET  `account` = ? , `password` = ?  WHERE id = ?",
+			args: 3,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.exec(); err != nil {
+				t.Fatalf("typed sql fail : %v", err)
+			}
+			if profileModel.LastSQL() != tc.sql || len(profileModel.LastArgs()) != tc.args {
+				t.Fatalf("typed sql fail : %q %v", profileModel.LastSQL(), profileModel.LastArgs())
+			}
+		})
+	}
+
+	t.Run("no primary key", func(t *testing.T) {
+		logModel := NewTypedModel[logEntry](nil).FetchSQL()
+		if _, err := logModel.Update(logEntry{Message: "hi"}); err == nil || logModel.Error() == nil {
+			t.Fatalf("update without primary key should fail")
+		}
+	})
+
+	t.Run("nil pointer", func(t *testing.T) {
+		pointerModel := NewTypedModel[*userProfile](nil).FetchSQL()
+		if _, err := pointerModel.Insert(nil); err == nil {
+			t.Fatalf("insert nil pointer should fail")
+		}
+		if _, err := pointerModel.Update(nil); err == nil {
+			t.Fatalf("update nil pointer should fail")
+		}
+	})
+}
@@ -0,0 +1,78 @@
+package mysqlgo
+
+import (
+	"fmt"
+)
+
+//unionPart 合并的查询，query为nil时sql为原样写入的语句
+type unionPart struct {
+	sql   string
+	query *Model
+	all   bool
+}
+
+//Union 合并Select，保留以兼容旧的代码，通过 UnionSelect 应用
+//
+//Deprecated: 合并其他查询使用 UnionQuery、UnionAll，原样写入的语句使用 Model.Union
+type Union struct {
+	SelectSQL []string
+	All       bool
+}
+
+//UnionSelect 应用旧的Union结构，与 UnionQuery、UnionAll 生成相同形式的语句
+//
+//Deprecated: 使用 UnionQuery、UnionAll
+func (m *Model) UnionSelect(union Union) *Model {
+	return m.Union(union.SelectSQL, union.All)
+}
+
+//UnionQuery 合并其他查询的结果集并去除重复的行
+///当前查询的Order、Limit作用于合并后的结果集
+func (m *Model) UnionQuery(queries ...*Model) *Model {
+	return m.union(false, queries)
+}
+
+//UnionAll 合并其他查询的结果集并保留重复的行
+///当前查询的Order、Limit作用于合并后的结果集
+func (m *Model) UnionAll(queries ...*Model) *Model {
+	return m.union(true, queries)
+}
+
+func (m *Model) union(all bool, queries []*Model) *Model {
+	q := m.clone()
+	for _, query := range queries {
+		if query == nil {
+			continue
+		}
+		q.options.unions = append(q.options.unions, unionPart{query: query, all: all})
+	}
+	return q
+}
+
+//parseUnionSQL 生成合并查询的语句
+///有Order、Limit时，合并的结果集作为派生表排序和分页
+func (m *Model) parseUnionSQL(sql string, options *option) string {
+	first := *options
+	first.unions = nil
+	first.cteSQL = ""
+	first.comment = ""
+	first.nested = true
+	first.order = nil
+	first.limit = Limit{}
+	union := m.parseSelectSQL(sql, &first)
+	for _, part := range options.unions {
+		keyword := "UNION"
+		if part.all {
+			keyword = "UNION ALL"
+		}
+		if part.query != nil {
+			union = fmt.Sprintf("%s %s (%s)", union, keyword, part.sql)
+		} else {
+			union = fmt.Sprintf("%s %s %s", union, keyword, part.sql)
+		}
+	}
+	if len(options.order) > 0 || options.limit.Offset > 0 {
+		union = fmt.Sprintf("SELECT * FROM (%s) AS `union_result`%s%s", union, m.parseOrder(options.order...), m.parseLimit(options.limit))
+	}
+	return m.parsePrefix(options.cteSQL + union + m.parseComment(options))
+}
@@ -0,0 +1,74 @@
+package mysqlgo
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUnion(t *testing.T) {
+	userModel := &Model{
+		TableName: "b_user",
+	}
+	archiveModel := &Model{
+		TableName: "b_user_archive",
+	}
+
+	t.Run("union query", func(t *testing.T) {
+		query := userModel.Field("id", "account").Where("status = ?", 1).
+			UnionAll(archiveModel.Field("id", "account").Where("status = ?", 2)).
+			UnionQuery(archiveModel.Field("id", "account").Where("level = ?", 3).Order(Order{Field: "id"}).Limit(Limit{Offset: 5})).
+			Order(Order{Field: "id", Desc: true}).
+			Limit(Limit{Offset: 10, Length: 20})
+		options, errs := query.prepare()
+		if len(errs) > 0 {
+			t.Fatalf("union query fail : %v", errs)
+		}
+		sql := query.parseSelectSQL(selectSQL, options)
+		for _, part := range []string{
+			"SELECT * FROM (SELECT `id`,`account` FROM b_user WHERE status = ? ",
+			" UNION ALL (SELECT `id`,`account` FROM b_user_archive WHERE status = ? ",
+			" UNION (SELECT `id`,`account` FROM b_user_archive WHERE level = ?  ORDER BY  `id` asc  Limit 5 ",
+			") AS `union_result` ORDER BY  `id` desc  LIMIT 10, 20 ",
+		} {
+			if !strings.Contains(sql, part) {
+				t.Fatalf("union query sql fail : %s, sql : %s", part, sql)
+			}
+		}
+		if strings.Count(sql, "UNION") != 2 {
+			t.Fatalf("union keyword fail : %s", sql)
+		}
+		if !reflect.DeepEqual(options.args(), []interface{}{1, 2, 3}) {
+			t.Fatalf("union args fail : %v", options.args())
+		}
+	})
+
+	t.Run("union count", func(t *testing.T) {
+		query := userModel.Field("id").UnionAll(archiveModel.Field("id"))
+		query.Count()
+		want := "SELECT COUNT(*) FROM (SELECT `id` FROM b_user "
+		if !strings.HasPrefix(query.LastSQL(), want) || !strings.Contains(query.LastSQL(), "UNION ALL (SELECT `id` FROM b_user_archive ") {
+			t.Fatalf("union count fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("raw union", func(t *testing.T) {
+		query := userModel.Union([]string{"SELECT * FROM b_user_a", "SELECT * FROM b_user_b"}, false)
+		query.Select(&[]struct{ ID int }{})
+		if !strings.Contains(query.LastSQL(), " UNION SELECT * FROM b_user_a UNION SELECT * FROM b_user_b") {
+			t.Fatalf("raw union fail : %s", query.LastSQL())
+		}
+		query = userModel.UnionSelect(Union{SelectSQL: []string{"SELECT * FROM b_user_a"}, All: true})
+		query.Select(&[]struct{ ID int }{})
+		if !strings.Contains(query.LastSQL(), " UNION ALL SELECT * FROM b_user_a") {
+			t.Fatalf("deprecated union fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("union lock", func(t *testing.T) {
+		query := userModel.Field("id").UnionAll(archiveModel.Field("id")).LockForUpdate().Tx(&Tx{})
+		if _, errs := query.prepare(); len(errs) != 1 || !strings.Contains(errs[0].Error(), "union") {
+			t.Fatalf("union lock should fail : %v", errs)
+		}
+	})
+}
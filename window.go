@@ -0,0 +1,220 @@
+package mysqlgo
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+//FrameRunning 从分区的第一行到当前行，用于累计求和等
+const FrameRunning = "ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW"
+
+//Window 窗口定义(MySQL 8)
+type Window struct {
+	Partition []string //PARTITION BY 字段
+	Order     []Order  //窗口内的排序
+	Frame     string   //窗口范围，如 FrameRunning、"ROWS BETWEEN 1 PRECEDING AND 1 FOLLOWING"
+}
+
+//WindowFunc 窗口函数表达式，通过 FieldWindow 加入查询字段
+///如 FieldWindow(RowNumber().Over(Window{Partition: []string{"user_id"}, Order: []Order{{Field: "id"}}}).As("rn"))
+type WindowFunc struct {
+	function string
+	args     []string
+	window   *Window
+	name     string
+	alias    string
+	err      error
+}
+
+type namedWindow struct {
+	name   string
+	window Window
+}
+
+var frameBound = `(UNBOUNDED\s+PRECEDING|UNBOUNDED\s+FOLLOWING|CURRENT\s+ROW|\d+\s+PRECEDING|\d+\s+FOLLOWING)`
+
+var windowFrame = regexp.MustCompile(`(?i)^(ROWS|RANGE)\s+(` + frameBound + `|BETWEEN\s+` + frameBound + `\s+AND\s+` + frameBound + `)$`)
+
+func newWindowFunc(function string, fields ...string) *WindowFunc {
+	f := &WindowFunc{function: function}
+	for _, field := range fields {
+		arg, err := quoteAggregate(field)
+		if err != nil {
+			f.err = err
+			return f
+		}
+		f.args = append(f.args, arg)
+	}
+	return f
+}
+
+//RowNumber 分区内的行号
+func RowNumber() *WindowFunc {
+	return newWindowFunc("ROW_NUMBER")
+}
+
+//Rank 分区内的排名，相同的值排名相同且会跳过后续的排名
+func Rank() *WindowFunc {
+	return newWindowFunc("RANK")
+}
+
+//DenseRank 分区内的排名，相同的值排名相同且不跳过后续的排名
+func DenseRank() *WindowFunc {
+	return newWindowFunc("DENSE_RANK")
+}
+
+//Lag 分区内当前行之前第offset行的值
+func Lag(field string, offset int) *WindowFunc {
+	f := newWindowFunc("LAG", field)
+	f.args = append(f.args, fmt.Sprint(offset))
+	return f
+}
+
+//Lead 分区内当前行之后第offset行的值
+func Lead(field string, offset int) *WindowFunc {
+	f := newWindowFunc("LEAD", field)
+	f.args = append(f.args, fmt.Sprint(offset))
+	return f
+}
+
+//WindowSum 窗口内求和，配合 FrameRunning 为累计求和
+func WindowSum(field string) *WindowFunc {
+	return newWindowFunc("SUM", field)
+}
+
+//WindowAvg 窗口内求平均值
+func WindowAvg(field string) *WindowFunc {
+	return newWindowFunc("AVG", field)
+}
+
+//WindowCount 窗口内统计数量
+func WindowCount(field string) *WindowFunc {
+	return newWindowFunc("COUNT", field)
+}
+
+//Over 指定窗口
+func (f *WindowFunc) Over(window Window) *WindowFunc {
+	c := *f
+	c.window, c.name = &window, ""
+	return &c
+}
+
+//OverWindow 使用 Model.Window 定义的命名窗口
+func (f *WindowFunc) OverWindow(name string) *WindowFunc {
+	c := *f
+	c.window, c.name = nil, name
+	if !identifierPart.MatchString(name) && c.err == nil {
+		c.err = fmt.Errorf("%s : %w", name, ErrInvalidIdentifier)
+	}
+	return &c
+}
+
+//As 指定字段别名
+func (f *WindowFunc) As(alias string) *WindowFunc {
+	c := *f
+	c.alias = alias
+	if !identifierPart.MatchString(alias) && c.err == nil {
+		c.err = fmt.Errorf("%s : %w", alias, ErrInvalidIdentifier)
+	}
+	return &c
+}
+
+func (f *WindowFunc) parse() (string, error) {
+	if f.err != nil {
+		return "", f.err
+	}
+	var over string
+	switch {
+	case f.name != "":
+		over = "`" + strings.Trim(f.name, "`") + "`"
+	case f.window != nil:
+		spec, err := f.window.parse()
+		if err != nil {
+			return "", err
+		}
+		over = fmt.Sprintf("(%s)", spec)
+	default:
+		return "", fmt.Errorf("The window of %s is null", f.function)
+	}
+	sql := fmt.Sprintf("%s(%s) OVER %s", f.function, strings.Join(f.args, ", "), over)
+	if f.alias != "" {
+		sql = fmt.Sprintf("%s AS `%s`", sql, strings.Trim(f.alias, "`"))
+	}
+	return sql, nil
+}
+
+func (w Window) parse() (string, error) {
+	var parts []string
+	if len(w.Partition) > 0 {
+		fields, err := quoteFields(w.Partition)
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, "PARTITION BY "+strings.Join(fields, ", "))
+	}
+	if len(w.Order) > 0 {
+		var orders []string
+		for _, order := range w.Order {
+			field, err := quoteIdentifier(order.Field)
+			if err != nil {
+				return "", err
+			}
+			if order.Desc {
+				orders = append(orders, field+" DESC")
+			} else {
+				orders = append(orders, field+" ASC")
+			}
+		}
+		parts = append(parts, "ORDER BY "+strings.Join(orders, ", "))
+	}
+	if w.Frame != "" {
+		frame := strings.Join(strings.Fields(w.Frame), " ")
+		if !windowFrame.MatchString(frame) {
+			return "", fmt.Errorf("The window frame `%s` is invalid", w.Frame)
+		}
+		parts = append(parts, strings.ToUpper(frame))
+	}
+	return strings.Join(parts, " "), nil
+}
+
+//FieldWindow 追加窗口函数到查询字段
+func (m *Model) FieldWindow(funcs ...*WindowFunc) *Model {
+	q := m.clone()
+	for _, f := range funcs {
+		sql, err := f.parse()
+		if err != nil {
+			q.options.err = append(q.options.err, fmt.Errorf("[Model FieldWindow]: %w", err))
+			continue
+		}
+		q.options.rawFields = append(q.options.rawFields, sql)
+	}
+	return q
+}
+
+//Window 定义命名窗口，生成 WINDOW name AS (...)，窗口函数通过 OverWindow 引用
+func (m *Model) Window(name string, window Window) *Model {
+	q := m.clone()
+	if !identifierPart.MatchString(name) {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Window]: %s : %w", name, ErrInvalidIdentifier))
+		return q
+	}
+	if _, err := window.parse(); err != nil {
+		q.options.err = append(q.options.err, fmt.Errorf("[Model Window]: %w", err))
+		return q
+	}
+	q.options.windows = append(q.options.windows, namedWindow{name: strings.Trim(name, "`"), window: window})
+	return q
+}
+
+func (m *Model) parseWindow(windows ...namedWindow) string {
+	if len(windows) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, w := range windows {
+		spec, _ := w.window.parse()
+		parts = append(parts, fmt.Sprintf("`%s` AS (%s)", w.name, spec))
+	}
+	return fmt.Sprintf(" WINDOW %s", strings.Join(parts, ", "))
+}
@@ -0,0 +1,70 @@
+package mysqlgo
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestWindow(t *testing.T) {
+	orderModel := &Model{
+		TableName: "b_order",
+	}
+
+	t.Run("window func", func(t *testing.T) {
+		byUser := Window{Partition: []string{"user_id"}, Order: []Order{{Field: "created_at", Desc: true}}}
+		testCases := []struct {
+			fn  *WindowFunc
+			sql string
+		}{
+			{fn: RowNumber().Over(byUser).As("rn"), sql: "ROW_NUMBER() OVER (PARTITION BY `user_id` ORDER BY `created_at` DESC) AS `rn`"},
+			{fn: Rank().OverWindow("w"), sql: "RANK() OVER `w`"},
+			{fn: Lag("amount", 1).Over(Window{Order: []Order{{Field: "id"}}}).As("prev"), sql: "LAG(`amount`, 1) OVER (ORDER BY `id` ASC) AS `prev`"},
+			{fn: WindowSum("o.amount").Over(Window{Order: []Order{{Field: "id"}}, Frame: "rows between unbounded preceding and current row"}),
+				sql: "SUM(`o`.`amount`) OVER (ORDER BY `id` ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW)"},
+		}
+		for _, testCase := range testCases {
+			sql, err := testCase.fn.parse()
+			if err != nil || sql != testCase.sql {
+				t.Fatalf("window func fail case : %s, sql : %s, err : %v", testCase.sql, sql, err)
+			}
+		}
+
+		invalids := []*WindowFunc{
+			RowNumber(),
+			Lead("amount; DROP", 1).OverWindow("w"),
+			RowNumber().Over(Window{Frame: "ROWS 1; DROP"}),
+			RowNumber().Over(byUser).As("rn)"),
+		}
+		for i, fn := range invalids {
+			if _, err := fn.parse(); err == nil {
+				t.Fatalf("window func validate fail case : %d", i)
+			}
+		}
+	})
+
+	t.Run("named window", func(t *testing.T) {
+		query := orderModel.Field("id").
+			FieldWindow(WindowSum("amount").OverWindow("running").As("total"), DenseRank().OverWindow("running")).
+			Window("running", Window{Partition: []string{"user_id"}, Order: []Order{{Field: "id"}}, Frame: FrameRunning}).
+			Order(Order{Field: "id"})
+		query.Select(&[]struct{ ID int }{})
+		want := "SELECT `id`,SUM(`amount`) OVER `running` AS `total`,DENSE_RANK() OVER `running` FROM b_order WINDOW `running` AS (PARTITION BY `user_id` ORDER BY `id` ASC ROWS BETWEEN UNBOUNDED PRECEDING AND CURRENT ROW) ORDER BY  `id` asc"
+		if !strings.Contains(query.LastSQL(), want) {
+			t.Fatalf("named window fail : %s", query.LastSQL())
+		}
+	})
+
+	t.Run("field after window", func(t *testing.T) {
+		query := orderModel.FieldWindow(RowNumber().Over(Window{Order: []Order{{Field: "id"}}}).As("rn")).
+			FieldRaw("amount * 2 AS double_amount").
+			Field("id")
+		options, errs := query.prepare()
+		if len(errs) > 0 {
+			t.Fatalf("field after window fail : %v", errs)
+		}
+		want := "SELECT `id`,ROW_NUMBER() OVER (ORDER BY `id` ASC) AS `rn`,amount * 2 AS double_amount FROM b_order"
+		if sql := query.parseSelectSQL(selectSQL, options); !strings.HasPrefix(sql, want) {
+			t.Fatalf("field after window fail : %s", sql)
+		}
+	})
+}